@@ -1,25 +1,64 @@
 package main
 
 import (
-	_ "github.com/joho/godotenv/autoload"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
 
 	"github.com/qysp/disgotify/pkg/common"
 	"github.com/qysp/disgotify/pkg/core"
 )
 
 func main() {
-	// Load env variables.
-	common.LoadEnv()
+	registerCommands := flag.Bool("register-commands", false, "print the slash command registration JSON and exit")
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "optional JSON, YAML or TOML config file, overridden by env variables")
+	flag.Parse()
+
+	// Load the config file, the .env file and env variables.
+	config, err := common.LoadConfig(*configFile)
+
+	// Emit the slash command registrations, e.g. to PUT them to Discord's application commands endpoint.
+	// Only the plugin directory of the config is needed, so other problems are ignored.
+	if *registerCommands {
+		if err := printApplicationCommands(os.Stdout, config); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Report all problems of the config at once.
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Initialize the global logger.
-	common.InitLogger(common.Debug)
+	common.InitLogger(config.Debug)
 
 	// Open connection to database and migrate.
-	common.InitDB()
+	common.InitDB(config.DatabaseDir)
+
+	// Load holiday calendars.
+	common.InitHolidays(config.HolidayDir)
 
 	// Start the Discord bot.
-	core.Start()
+	core.Start(config)
 
 	// Disconnect client and close database on interrupt.
 	core.StopOnInterrupt()
 }
+
+// printApplicationCommands writes the slash command registrations of all commands, including plugins, to w.
+func printApplicationCommands(w io.Writer, config *common.Config) error {
+	// Loading plugins logs, so the logger is needed before the index.
+	common.InitLogger(config.Debug)
+	core.InitIndex(config)
+
+	data, err := core.ApplicationCommandsJSON()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+const testPlugin = `#!/bin/sh
+echo '{"name": "dice", "description": "Roll a die.", "arguments": [{"name": "sides", "type": "integer", "optional": true}]}'
+`
+
+func TestPrintApplicationCommands(t *testing.T) {
+	dir, err := ioutil.TempDir("", "disgotify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The log file is created in the working directory.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	pluginDir := filepath.Join(dir, "plugins")
+	if err := os.Mkdir(pluginDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(pluginDir, "dice"), []byte(testPlugin), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := printApplicationCommands(&out, &common.Config{PluginDir: pluginDir}); err != nil {
+		t.Fatal(err)
+	}
+
+	var registrations []struct {
+		Name    string `json:"name"`
+		Options []struct {
+			Name string `json:"name"`
+		} `json:"options"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &registrations); err != nil {
+		t.Fatalf("malformed output: %s", err)
+	}
+
+	found := map[string]bool{}
+	for _, r := range registrations {
+		found[r.Name] = true
+		if r.Name == "dice" && (len(r.Options) != 1 || r.Options[0].Name != "sides") {
+			t.Errorf("dice options = %+v, want [sides]", r.Options)
+		}
+	}
+	for _, name := range []string{"ping", "reminder", "dice"} {
+		if !found[name] {
+			t.Errorf("registration of %s missing", name)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Command interactionfixture signs interaction fixtures like Discord does and sends them to a local interaction server.
+//
+// Generate a key pair and start the bot with the printed public key as DISCORD_PUBLIC_KEY:
+//
+//	interactionfixture -keygen
+//
+// Send a signed fixture and print the response:
+//
+//	interactionfixture -key [private key] testdata/interactions/ping.json
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func main() {
+	keygen := flag.Bool("keygen", false, "generate a key pair and exit")
+	key := flag.String("key", os.Getenv("INTERACTIONS_PRIVATE_KEY"), "hex encoded private key used to sign the fixture")
+	url := flag.String("url", "http://localhost:8080/interactions", "URL of the interaction server")
+	tamper := flag.Bool("tamper", false, "alter the body after signing, the server has to reject the request")
+	flag.Parse()
+
+	if *keygen {
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("DISCORD_PUBLIC_KEY=%s\n", hex.EncodeToString(public))
+		fmt.Printf("INTERACTIONS_PRIVATE_KEY=%s\n", hex.EncodeToString(private))
+		return
+	}
+
+	if flag.NArg() != 1 {
+		log.Fatal("usage: interactionfixture -key [private key] [fixture.json]")
+	}
+
+	private, err := hex.DecodeString(*key)
+	if err != nil || len(private) != ed25519.PrivateKeySize {
+		log.Fatal("invalid private key, generate one with -keygen")
+	}
+
+	body, err := ioutil.ReadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := ed25519.Sign(private, append([]byte(timestamp), body...))
+	if *tamper {
+		body = append(body, ' ')
+	}
+
+	req, err := http.NewRequest(http.MethodPost, *url, bytes.NewReader(body))
+	if err != nil {
+		log.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature-Ed25519", hex.EncodeToString(signature))
+	req.Header.Set("X-Signature-Timestamp", timestamp)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	response, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(res.Status)
+	fmt.Println(string(response))
+}
@@ -3,13 +3,15 @@ module github.com/qysp/disgotify
 go 1.12
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/andersfylling/disgord v0.11.1
 	github.com/jinzhu/gorm v1.9.9
 	github.com/joho/godotenv v0.0.0-20190204044109-5c0e6c6ab1a0
 	github.com/nleeper/goment v0.0.0-20190304152151-62477c661bec
 	go.uber.org/atomic v1.4.0 // indirect
 	go.uber.org/zap v1.9.1
-	golang.org/x/crypto v0.0.0-20190611184440-5c40567a22f8 // indirect
+	golang.org/x/crypto v0.0.0-20190611184440-5c40567a22f8
 	golang.org/x/net v0.0.0-20190613194153-d28f0bde5980 // indirect
 	golang.org/x/sys v0.0.0-20190616124812-15dcb6c0061f // indirect
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,132 @@
+package block
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+// maxReason is the maximum length of a block reason.
+const maxReason = 200
+
+// Block user blocking (unblock is false) or unblocking command.
+type Block struct {
+	unblock bool
+}
+
+func Init() *Block {
+	return &Block{}
+}
+
+// InitUnblock initializes the unblock command.
+func InitUnblock() *Block {
+	return &Block{unblock: true}
+}
+
+func (c *Block) Name() string {
+	if c.unblock {
+		return "unblock"
+	}
+	return "block"
+}
+
+func (*Block) Aliases() []string {
+	return []string{}
+}
+
+func (c *Block) Description() string {
+	if c.unblock {
+		return "Stop ignoring a blocked user."
+	}
+	return "Make the bot ignore a user's commands and reminders."
+}
+
+func (*Block) Category() common.CommandCategory {
+	return common.CategoryGeneral
+}
+
+func (*Block) Permission() common.PermissionLevel {
+	return common.PermissionDeveloper
+}
+
+func (*Block) Active() bool {
+	return true
+}
+
+func (c *Block) Execute(s common.MessageState) {
+	if c.unblock {
+		c.unblockUser(s)
+		return
+	}
+
+	userID := s.Args.Snowflake("user")
+	if s.Config.IsOwner(userID) || userID == common.BotID {
+		s.Reply("Sorry, owners and the bot itself cannot be blocked!")
+		return
+	}
+
+	reason := strings.TrimSpace(s.Args.String("reason"))
+	if len(reason) > maxReason {
+		s.Reply(fmt.Sprintf("Sorry, the reason can't be longer than %d characters!", maxReason))
+		return
+	}
+
+	err := common.BlockUser(userID, s.UserID(), reason)
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+	s.Reply(fmt.Sprintf("Blocked <@%s>, their commands and reminders are ignored now.", userID))
+}
+
+// unblockUser removes a user from the blocklist.
+func (*Block) unblockUser(s common.MessageState) {
+	userID := s.Args.Snowflake("user")
+	unblocked, err := common.UnblockUser(userID)
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+	if !unblocked {
+		s.Reply(fmt.Sprintf("<@%s> is not blocked.", userID))
+		return
+	}
+	s.Reply(fmt.Sprintf("Unblocked <@%s>.", userID))
+}
+
+func (c *Block) Usage() common.CommandUsage {
+	if c.unblock {
+		return common.CommandUsage{
+			Arguments: []common.Argument{
+				{
+					Name:        "user",
+					Description: "Mention or ID of the blocked user",
+					Type:        common.ArgumentUser,
+				},
+			},
+			Examples: []common.UsageExample{
+				{Description: "Unblocking a user", Args: "@someone"},
+			},
+		}
+	}
+
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "user",
+				Description: "Mention or ID of the user to block",
+				Type:        common.ArgumentUser,
+			},
+			{
+				Name:        "reason",
+				Description: "Why the user is blocked",
+				Type:        common.ArgumentText,
+				Optional:    true,
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Blocking a user", Args: "@someone spamming minutely reminders"},
+		},
+	}
+}
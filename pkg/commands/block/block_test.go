@@ -0,0 +1,31 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+func TestBlockRequiresUser(t *testing.T) {
+	// Listing must not look like blocking, so block has no alias listing the users and needs a user.
+	for _, alias := range Init().Aliases() {
+		if alias == InitBlocklist().Name() {
+			t.Errorf("the blocklist command must not be an alias of block")
+		}
+	}
+
+	_, err := common.ParseArguments(Init().Usage().Arguments, nil)
+	if argErr, ok := err.(*common.ArgumentError); !ok || !argErr.Missing {
+		t.Errorf("got %v, want the user to be missing", err)
+	}
+	if _, err := common.ParseArguments(Init().Usage().Arguments, []string{"<@2>", "spam"}); err != nil {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestBlocklistArguments(t *testing.T) {
+	// Arguments given to blocklist are never taken as a user to block.
+	if args := InitBlocklist().Usage().Arguments; len(args) != 0 {
+		t.Errorf("blocklist must not take arguments, got %+v", args)
+	}
+}
@@ -0,0 +1,85 @@
+package block
+
+import (
+	"fmt"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+// Blocklist command listing the blocked users.
+type Blocklist struct{}
+
+// InitBlocklist initializes the blocklist command.
+func InitBlocklist() *Blocklist {
+	return &Blocklist{}
+}
+
+func (*Blocklist) Name() string {
+	return "blocklist"
+}
+
+func (*Blocklist) Aliases() []string {
+	return []string{"blocked"}
+}
+
+func (*Blocklist) Description() string {
+	return "List the users the bot ignores."
+}
+
+func (*Blocklist) Category() common.CommandCategory {
+	return common.CategoryGeneral
+}
+
+func (*Blocklist) Permission() common.PermissionLevel {
+	return common.PermissionDeveloper
+}
+
+func (*Blocklist) Active() bool {
+	return true
+}
+
+func (*Blocklist) Execute(s common.MessageState) {
+	users, err := common.GetBlockedUsers()
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	if len(users) == 0 {
+		s.Reply("No users are blocked.")
+		return
+	}
+
+	var fields []*disgord.EmbedField
+	for _, user := range users {
+		reason := user.Reason
+		if reason == "" {
+			reason = "No reason given"
+		}
+		fields = append(fields, &disgord.EmbedField{
+			Name: user.UserID.String(),
+			Value: fmt.Sprintf(
+				"<@%s> blocked by <@%s> on %s: %s",
+				user.UserID,
+				user.BlockedBy,
+				user.CreatedAt.Format("2006-01-02"),
+				reason,
+			),
+		})
+	}
+
+	s.SendPaginated(&disgord.Embed{
+		Title:  "Blocked users:",
+		Color:  0xe5004c,
+		Fields: fields,
+	})
+}
+
+func (*Blocklist) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Examples: []common.UsageExample{
+			{Description: "Listing the blocked users"},
+		},
+	}
+}
@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"time"
+
 	"github.com/qysp/disgotify/pkg/common"
 )
 
@@ -15,6 +17,9 @@ type Command interface {
 	// Description represents a function which should return a short command description.
 	Description() string
 
+	// Category represents a function which should return the category the command is listed under.
+	Category() common.CommandCategory
+
 	// Permission represents a function which should return the command's required permission level.
 	Permission() common.PermissionLevel
 
@@ -22,8 +27,28 @@ type Command interface {
 	Active() bool
 
 	// Execute represents a function which should execute the response of a requested command.
+	// It should stop once MessageState.Context() is done, e.g. by passing it to database transactions.
 	Execute(common.MessageState)
 
-	// Help represents a function which should send a help/usage message of the command to a channel.
-	Help(common.MessageState)
+	// Usage represents a function which should return the declared arguments, notes and examples of the command.
+	// Arguments are validated and parsed into MessageState.Args before Execute is called.
+	Usage() common.CommandUsage
+}
+
+// TimeoutCommand represents a command which needs a different deadline than the default.
+type TimeoutCommand interface {
+	Command
+
+	// Timeout represents a function which should return the time the command has to finish.
+	// It can be replaced by configuration.
+	Timeout() time.Duration
+}
+
+// CooldownCommand represents a command which limits how often it can be used.
+type CooldownCommand interface {
+	Command
+
+	// Cooldowns represents a function which should return the default cooldowns of the command.
+	// They can be replaced by configuration.
+	Cooldowns() []common.Cooldown
 }
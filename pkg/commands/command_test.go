@@ -0,0 +1,24 @@
+package commands
+
+import (
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+// testCommand is a command which records its executions.
+type testCommand struct {
+	name       string
+	aliases    []string
+	category   common.CommandCategory
+	permission common.PermissionLevel
+	usage      common.CommandUsage
+	executed   int
+}
+
+func (c *testCommand) Name() string                       { return c.name }
+func (c *testCommand) Aliases() []string                  { return c.aliases }
+func (c *testCommand) Description() string                { return "The " + c.name + " command." }
+func (c *testCommand) Category() common.CommandCategory   { return c.category }
+func (c *testCommand) Permission() common.PermissionLevel { return c.permission }
+func (c *testCommand) Active() bool                       { return true }
+func (c *testCommand) Execute(common.MessageState)        { c.executed++ }
+func (c *testCommand) Usage() common.CommandUsage         { return c.usage }
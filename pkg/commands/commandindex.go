@@ -1,10 +1,21 @@
 package commands
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/qysp/disgotify/pkg/commands/block"
+	"github.com/qysp/disgotify/pkg/commands/holidays"
 	"github.com/qysp/disgotify/pkg/commands/list"
+	"github.com/qysp/disgotify/pkg/commands/permissions"
 	"github.com/qysp/disgotify/pkg/commands/ping"
+	"github.com/qysp/disgotify/pkg/commands/plugin"
+	"github.com/qysp/disgotify/pkg/commands/prefix"
 	"github.com/qysp/disgotify/pkg/commands/remind"
 	"github.com/qysp/disgotify/pkg/commands/remove"
+	"github.com/qysp/disgotify/pkg/commands/stats"
+	"github.com/qysp/disgotify/pkg/commands/suggestions"
+	"github.com/qysp/disgotify/pkg/common"
 )
 
 // CommandIndex represents the index for bot commands mapped with their name and aliases.
@@ -13,23 +24,58 @@ type CommandIndex map[string]Command
 // CommandList represents a list of all unique bot command.
 var CommandList []Command
 
-// Init initialize the command index.
+// Init initialize the command index, including the plugins of the configured plugin directory.
 // Commands are registered by name as well as alias.
-func Init() *CommandIndex {
+func Init(config *common.Config) *CommandIndex {
 	index := &CommandIndex{}
 
-	index.register(
+	index.Register(
 		ping.Init(),
 		remind.Init(),
 		list.Init(),
 		remove.Init(),
+		holidays.Init(),
+		prefix.Init(),
+		stats.Init(),
+		permissions.Init(),
+		block.Init(),
+		block.InitUnblock(),
+		block.InitBlocklist(),
+		NewGroup(
+			"reminder",
+			[]string{"reminders"},
+			"Add, list and remove your reminders.",
+			common.CategoryReminders,
+			Named("add", []string{"new"}, remind.Init()),
+			Named("list", []string{"ls"}, list.Init()),
+			Named("remove", []string{"rm", "delete", "del"}, remove.Init()),
+		),
+		NewGroup(
+			"settings",
+			[]string{"config"},
+			"Show and change the settings of this server.",
+			common.CategorySettings,
+			Named("holidays", []string{"holiday", "calendar"}, holidays.Init()),
+			suggestions.Init(),
+			prefix.Init(),
+			permissions.Init(),
+		),
 	)
 
+	// Plugins never replace built-in commands.
+	for _, p := range plugin.Load(config.PluginDir) {
+		if index.Has(p.Name()) {
+			common.Logger.Warn(fmt.Sprintf("Plugin %s is already the name of a command", p.Name()))
+			continue
+		}
+		index.Register(p)
+	}
+
 	return index
 }
 
-// register adds commands to the command index.
-func (ci *CommandIndex) register(commands ...Command) {
+// Register adds commands to the command index.
+func (ci *CommandIndex) Register(commands ...Command) {
 	for _, cmd := range commands {
 		if !cmd.Active() {
 			continue
@@ -38,12 +84,48 @@ func (ci *CommandIndex) register(commands ...Command) {
 		// Make a list of all commands without their aliases.
 		CommandList = append(CommandList, cmd)
 
-		ci.Set(cmd.Name(), cmd)
-		for _, alias := range cmd.Aliases() {
-			if !ci.Has(alias) {
-				ci.Set(alias, cmd)
-			}
+		ci.add(cmd)
+	}
+}
+
+// add sets a command by name as well as alias, aliases never replace other commands.
+func (ci *CommandIndex) add(cmd Command) {
+	ci.Set(cmd.Name(), cmd)
+	for _, alias := range cmd.Aliases() {
+		if !ci.Has(alias) {
+			ci.Set(alias, cmd)
+		}
+	}
+}
+
+// Resolve returns the command addressed by the leading words of args, descending into command groups.
+// The path consists of the command names (not aliases) and the remaining words are the command's arguments.
+// If no command matches, nil is returned.
+func (ci *CommandIndex) Resolve(args []string) (cmd Command, path []string, rest []string) {
+	if len(args) == 0 {
+		return nil, nil, nil
+	}
+
+	cmd = ci.Get(strings.ToLower(args[0]))
+	if cmd == nil {
+		return nil, nil, nil
+	}
+	path = []string{cmd.Name()}
+	rest = args[1:]
+
+	for {
+		group, ok := cmd.(*Group)
+		if !ok || len(rest) == 0 {
+			return cmd, path, rest
+		}
+
+		sub := group.Subcommand(rest[0])
+		if sub == nil {
+			return cmd, path, rest
 		}
+		cmd = sub
+		path = append(path, sub.Name())
+		rest = rest[1:]
 	}
 }
 
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+// Group represents a command which only consists of subcommands, e.g. "reminder add".
+type Group struct {
+	name        string
+	aliases     []string
+	description string
+	category    common.CommandCategory
+	subcommands []Command
+	index       CommandIndex
+}
+
+// NewGroup creates a command group, subcommands are registered by name as well as alias.
+func NewGroup(name string, aliases []string, description string, category common.CommandCategory, subcommands ...Command) *Group {
+	g := &Group{
+		name:        name,
+		aliases:     aliases,
+		description: description,
+		category:    category,
+		index:       CommandIndex{},
+	}
+
+	for _, cmd := range subcommands {
+		if !cmd.Active() {
+			continue
+		}
+		g.subcommands = append(g.subcommands, cmd)
+		g.index.add(cmd)
+	}
+
+	return g
+}
+
+func (g *Group) Name() string {
+	return g.name
+}
+
+func (g *Group) Aliases() []string {
+	return g.aliases
+}
+
+func (g *Group) Description() string {
+	return g.description
+}
+
+func (g *Group) Category() common.CommandCategory {
+	return g.category
+}
+
+// Permission of a group is the lowest permission of its subcommands, which check their own permission.
+func (g *Group) Permission() common.PermissionLevel {
+	level := common.PermissionDeveloper
+	for _, cmd := range g.subcommands {
+		if cmd.Permission() < level {
+			level = cmd.Permission()
+		}
+	}
+	return level
+}
+
+func (g *Group) Active() bool {
+	return len(g.subcommands) > 0
+}
+
+// Execute is only called if no subcommand matched, it sends the help message of the group.
+func (g *Group) Execute(s common.MessageState) {
+	s.SendEmbed(HelpEmbed(g, g.name, s.GuildPrefix()))
+}
+
+func (g *Group) Usage() common.CommandUsage {
+	var names []string
+	for _, cmd := range g.subcommands {
+		names = append(names, cmd.Name())
+	}
+
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "subcommand",
+				Description: "One of the subcommands listed below",
+				Optional:    true,
+				Choices:     names,
+			},
+		},
+	}
+}
+
+// Subcommands returns the subcommands of the group in order of registration.
+func (g *Group) Subcommands() []Command {
+	return g.subcommands
+}
+
+// Subcommand returns the subcommand by name or alias.
+func (g *Group) Subcommand(name string) Command {
+	return g.index.Get(strings.ToLower(name))
+}
+
+// named represents a command registered under a different name, e.g. as a subcommand.
+type named struct {
+	Command
+	name    string
+	aliases []string
+}
+
+// Named returns cmd registered as name with aliases instead of its own name and aliases.
+func Named(name string, aliases []string, cmd Command) Command {
+	return &named{
+		Command: cmd,
+		name:    name,
+		aliases: aliases,
+	}
+}
+
+func (n *named) Name() string {
+	return n.name
+}
+
+func (n *named) Aliases() []string {
+	return n.aliases
+}
+
+// Unwrap returns the original command of a command registered under a different name.
+func Unwrap(cmd Command) Command {
+	if n, ok := cmd.(*named); ok {
+		return Unwrap(n.Command)
+	}
+	return cmd
+}
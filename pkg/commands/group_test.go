@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+func TestResolve(t *testing.T) {
+	add := &testCommand{name: "remind", aliases: []string{"r"}}
+	list := &testCommand{name: "list", aliases: []string{"ls"}, permission: common.PermissionDeveloper}
+	ping := &testCommand{name: "ping"}
+
+	index := &CommandIndex{}
+	index.Register(
+		ping,
+		add,
+		NewGroup("reminder", []string{"reminders"}, "Reminders.", common.CategoryReminders,
+			Named("add", []string{"new"}, add),
+			list,
+		),
+	)
+
+	tests := []struct {
+		args []string
+		cmd  Command
+		path []string
+		rest []string
+	}{
+		{[]string{"PING"}, ping, []string{"ping"}, []string{}},
+		{[]string{"r", "today", "9am"}, add, []string{"remind"}, []string{"today", "9am"}},
+		{[]string{"reminders", "new", "today"}, add, []string{"reminder", "add"}, []string{"today"}},
+		{[]string{"reminder", "LS"}, list, []string{"reminder", "list"}, []string{}},
+		{[]string{"unknown"}, nil, nil, nil},
+	}
+
+	for _, test := range tests {
+		cmd, path, rest := index.Resolve(test.args)
+		if Unwrap(cmd) != test.cmd && !(cmd == nil && test.cmd == nil) {
+			t.Errorf("Resolve(%q) returned command %v, want %v", test.args, cmd, test.cmd)
+		}
+		if !reflect.DeepEqual(path, test.path) || len(rest) != len(test.rest) {
+			t.Errorf("Resolve(%q) = %q, %q, want %q, %q", test.args, path, rest, test.path, test.rest)
+		}
+	}
+
+	// A group without a matching subcommand resolves to the group itself.
+	cmd, path, rest := index.Resolve([]string{"reminder", "edit"})
+	if _, ok := cmd.(*Group); !ok || !reflect.DeepEqual(path, []string{"reminder"}) || !reflect.DeepEqual(rest, []string{"edit"}) {
+		t.Errorf("Resolve of an unknown subcommand = %v, %q, %q", cmd, path, rest)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	group := NewGroup("settings", nil, "Settings.", common.CategorySettings,
+		&testCommand{name: "prefix", permission: common.PermissionAdmin},
+		&testCommand{name: "holidays", permission: common.PermissionModerator},
+	)
+
+	if group.Permission() != common.PermissionModerator {
+		t.Errorf("the permission of a group is the lowest of its subcommands, got %v", group.Permission())
+	}
+	if choices := group.Usage().Arguments[0].Choices; !reflect.DeepEqual(choices, []string{"prefix", "holidays"}) {
+		t.Errorf("subcommand choices = %q", choices)
+	}
+	if NewGroup("empty", nil, "", common.CategoryGeneral).Active() {
+		t.Error("a group without subcommands must be inactive")
+	}
+}
+
+func TestRegisterKeepsNames(t *testing.T) {
+	first := &testCommand{name: "list", aliases: []string{"l"}}
+	second := &testCommand{name: "lock", aliases: []string{"l", "list"}}
+
+	index := &CommandIndex{}
+	index.Register(first, second)
+
+	if index.Get("l") != first || index.Get("list") != first || index.Get("lock") != second {
+		t.Error("aliases must never replace other commands")
+	}
+}
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andersfylling/disgord"
+)
+
+// HelpEmbed generates the help/usage message of a command from its declared usage.
+// The path is the full name of the command, e.g. "reminder add" for subcommands,
+// and prefix is the command prefix effective where the message is sent.
+func HelpEmbed(cmd Command, path, prefix string) *disgord.Embed {
+	if group, ok := cmd.(*Group); ok {
+		return groupHelpEmbed(group, path, prefix)
+	}
+
+	usage := cmd.Usage()
+	invocation := prefix + path
+	fields := []*disgord.EmbedField{}
+
+	// Command aliases.
+	if len(cmd.Aliases()) > 0 {
+		fields = append(fields, &disgord.EmbedField{
+			Name:  "Aliases",
+			Value: strings.Join(cmd.Aliases(), ", "),
+		})
+	}
+
+	// Declared arguments.
+	synopsis := []string{invocation}
+	for _, arg := range usage.Arguments {
+		name := arg.Name
+		kind := arg.Type.String()
+		if arg.Optional {
+			name += "?"
+			kind += ", optional"
+		}
+		synopsis = append(synopsis, fmt.Sprintf("[%s]", name))
+
+		description := arg.Description
+		if len(arg.Choices) > 0 {
+			description += fmt.Sprintf(" (%s)", strings.Join(arg.Choices, ", "))
+		}
+		fields = append(fields, &disgord.EmbedField{
+			Name:  fmt.Sprintf("[%s] %s", strings.Title(arg.Name), kind),
+			Value: description,
+		})
+	}
+
+	// Additional notes.
+	for _, note := range usage.Notes {
+		fields = append(fields, &disgord.EmbedField{
+			Name:  note.Name,
+			Value: note.Value,
+		})
+	}
+
+	// Usage examples.
+	for _, example := range usage.Examples {
+		fields = append(fields, &disgord.EmbedField{
+			Name:  fmt.Sprintf("[Example] %s", example.Description),
+			Value: strings.TrimSpace(invocation + " " + example.Args),
+		})
+	}
+
+	return &disgord.Embed{
+		Title:       fmt.Sprintf("Command \"%s\" usage", path),
+		Description: strings.Join(synopsis, " "),
+		Color:       0xe5004c,
+		Fields:      fields,
+	}
+}
+
+// groupHelpEmbed generates the help message of a command group listing its subcommands.
+func groupHelpEmbed(group *Group, path, prefix string) *disgord.Embed {
+	invocation := prefix + path
+	fields := []*disgord.EmbedField{}
+
+	// Command aliases.
+	if len(group.Aliases()) > 0 {
+		fields = append(fields, &disgord.EmbedField{
+			Name:  "Aliases",
+			Value: strings.Join(group.Aliases(), ", "),
+		})
+	}
+
+	// Subcommands.
+	for _, cmd := range group.Subcommands() {
+		fields = append(fields, &disgord.EmbedField{
+			Name:  fmt.Sprintf("%s %s", path, cmd.Name()),
+			Value: cmd.Description(),
+		})
+	}
+
+	return &disgord.Embed{
+		Title: fmt.Sprintf("Command group \"%s\" usage", path),
+		Description: fmt.Sprintf(
+			"%s [subcommand]\nUse `%shelp %s [subcommand]` for the usage of a subcommand.",
+			invocation,
+			prefix,
+			path,
+		),
+		Color:  0xe5004c,
+		Fields: fields,
+	}
+}
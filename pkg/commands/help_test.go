@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+func TestHelpEmbed(t *testing.T) {
+	cmd := &testCommand{
+		name:    "remind",
+		aliases: []string{"r"},
+		usage: common.CommandUsage{
+			Arguments: []common.Argument{
+				{Name: "date", Description: "The date"},
+				{Name: "mode", Description: "The mode", Choices: []string{"on", "off"}},
+				{Name: "notification", Description: "Anything", Type: common.ArgumentText, Optional: true},
+			},
+			Notes:    []common.UsageNote{{Name: "Note", Value: "Value"}},
+			Examples: []common.UsageExample{{Description: "Reminding", Args: "today 9am"}},
+		},
+	}
+
+	embed := HelpEmbed(cmd, "remind", "+")
+	if embed.Description != "+remind [date] [mode] [notification?]" {
+		t.Errorf("synopsis = %q", embed.Description)
+	}
+
+	want := [][2]string{
+		{"Aliases", "r"},
+		{"[Date] Word", "The date"},
+		{"[Mode] Word", "The mode (on, off)"},
+		{"[Notification] Text, optional", "Anything"},
+		{"Note", "Value"},
+		{"[Example] Reminding", "+remind today 9am"},
+	}
+	if len(embed.Fields) != len(want) {
+		t.Fatalf("got %d fields, want %d", len(embed.Fields), len(want))
+	}
+	for i, field := range embed.Fields {
+		if field.Name != want[i][0] || field.Value != want[i][1] {
+			t.Errorf("field %d = %q: %q, want %q: %q", i, field.Name, field.Value, want[i][0], want[i][1])
+		}
+	}
+}
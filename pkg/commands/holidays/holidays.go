@@ -0,0 +1,131 @@
+package holidays
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+// Holidays holiday calendar selection command.
+type Holidays struct{}
+
+func Init() *Holidays {
+	return &Holidays{}
+}
+
+func (*Holidays) Name() string {
+	return "holidays"
+}
+
+func (*Holidays) Aliases() []string {
+	return []string{"holiday", "calendar"}
+}
+
+func (*Holidays) Description() string {
+	return "Show or select the holiday calendar used for business day reminders."
+}
+
+func (*Holidays) Category() common.CommandCategory {
+	return common.CategorySettings
+}
+
+func (*Holidays) Permission() common.PermissionLevel {
+	return common.PermissionDefault
+}
+
+func (*Holidays) Active() bool {
+	return true
+}
+
+func (c *Holidays) Execute(s common.MessageState) {
+	if !s.Args.Has("action") {
+		c.list(s)
+		return
+	}
+
+	if !s.Args.Has("calendar") {
+		s.Reply("Sorry, missing argument \"calendar\"!")
+		return
+	}
+
+	if s.GuildID().Empty() {
+		s.Reply("A holiday calendar can only be selected in a server.")
+		return
+	}
+
+	if s.UserPermission() < common.PermissionAdmin {
+		s.Reply("Only server admins can select the holiday calendar.")
+		return
+	}
+
+	name := strings.ToLower(s.Args.String("calendar"))
+	if name == "none" {
+		name = ""
+	} else if _, ok := common.Holidays[name]; !ok {
+		s.Reply(fmt.Sprintf("Unknown holiday calendar \"%s\".", name))
+		return
+	}
+
+	settings, err := common.GetGuildSettings(s.GuildID())
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	settings.HolidayCalendar = name
+	err = common.SaveGuildSettings(settings)
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	if name == "" {
+		s.Reply("This server no longer uses a holiday calendar.")
+		return
+	}
+	s.Reply(fmt.Sprintf("This server now uses the holiday calendar \"%s\".", name))
+}
+
+// list replies with all available calendars and the one currently in use.
+func (*Holidays) list(s common.MessageState) {
+	names := common.HolidayCalendarNames()
+	if len(names) == 0 {
+		s.Reply("There are no holiday calendars available.")
+		return
+	}
+
+	current := common.GuildHolidayCalendar(s.GuildID(), s.Config.DefaultHolidayCalendar)
+	if current == "" {
+		current = "none"
+	}
+
+	s.Reply(fmt.Sprintf(
+		"Available holiday calendars: %s. Currently in use: %s.",
+		strings.Join(names, ", "),
+		current,
+	))
+}
+
+func (*Holidays) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "action",
+				Description: "Select a holiday calendar for this server (server admins only)",
+				Optional:    true,
+				Choices:     []string{"set"},
+			},
+			{
+				Name:        "calendar",
+				Description: "Name of the holiday calendar, \"none\" removes the selection",
+				Optional:    true,
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Listing the available holiday calendars"},
+			{Description: "Selecting a holiday calendar (server admins only)", Args: "set germany"},
+			{Description: "Removing the holiday calendar of the server", Args: "set none"},
+		},
+	}
+}
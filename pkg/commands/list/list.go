@@ -3,8 +3,10 @@ package list
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/andersfylling/disgord"
+	"github.com/jinzhu/gorm"
 	"github.com/nleeper/goment"
 	"github.com/qysp/disgotify/pkg/common"
 	"github.com/qysp/disgotify/pkg/models"
@@ -29,6 +31,10 @@ func (*List) Description() string {
 	return "List all of your reminders (sent via DM)."
 }
 
+func (*List) Category() common.CommandCategory {
+	return common.CategoryReminders
+}
+
 func (*List) Permission() common.PermissionLevel {
 	return common.PermissionDefault
 }
@@ -37,15 +43,22 @@ func (*List) Active() bool {
 	return true
 }
 
+func (*List) Cooldowns() []common.Cooldown {
+	return []common.Cooldown{
+		{Scope: common.CooldownUser, Uses: 1, Period: 30 * time.Second},
+	}
+}
+
 func (*List) Execute(s common.MessageState) {
 	var reminders []models.Reminder
-	err := common.DB.Where(models.Reminder{
-		UserID: s.UserID(),
-	}).Find(&reminders).Error
+	err := common.Transaction(s.Context(), func(tx *gorm.DB) error {
+		return tx.Where(models.Reminder{
+			UserID: s.UserID(),
+		}).Find(&reminders).Error
+	})
 
 	if err != nil {
-		s.Session.Logger().Error(err)
-		s.Reply(fmt.Sprintf("Unexpected error: %s", err.Error()))
+		s.Fail(err)
 		return
 	}
 
@@ -56,51 +69,75 @@ func (*List) Execute(s common.MessageState) {
 
 	var fields []*disgord.EmbedField
 	// Use idx as personal reminder ID.
-	for idx, reminder := range reminders {
-		var repeat string
-		switch reminder.Repeat {
-		case models.RepeatMinutely:
-			repeat = "[Minutely] "
-		case models.RepeatHourly:
-			repeat = "[Hourly] "
-		case models.RepeatDaily:
-			repeat = "[Daily] "
+	for idx, slots := range models.GroupReminders(reminders) {
+		// The earliest slot is the next notification.
+		next := slots[0]
+		for _, slot := range slots[1:] {
+			if slot.Due < next.Due {
+				next = slot
+			}
+		}
+
+		due, _ := goment.Unix(next.Due)
+		value := next.Notification
+		if len(slots) > 1 {
+			var schedule []string
+			for _, slot := range slots {
+				schedule = append(schedule, formatSlot(slot))
+			}
+			value = fmt.Sprintf("%s\nSchedule: %s", value, strings.Join(schedule, ", "))
 		}
-		due, _ := goment.Unix(reminder.Due)
+
 		fields = append(fields, &disgord.EmbedField{
 			Name: fmt.Sprintf(
 				"%sReminder #%d on the %s at %s",
-				repeat,
+				repeatLabel(next.Repeat),
 				idx+1,
 				due.Format("Do MMMM YYYY"),
 				due.Format("HH:mm:ss"),
 			),
-			Value: reminder.Notification,
+			Value: value,
 		})
 	}
 
-	s.DMEmbed(&disgord.Embed{
+	s.DMPaginated(&disgord.Embed{
 		Title:  "List of your registered reminders:",
 		Color:  0xe5004c,
 		Fields: fields,
 	})
 }
 
-func (c *List) Help(s common.MessageState) {
-	cmd := common.CommandPrefix + c.Name()
-	fields := []*disgord.EmbedField{}
-
-	// Command aliases.
-	fields = append(fields, &disgord.EmbedField{
-		Name:  "Aliases",
-		Value: strings.Join(c.Aliases(), ", "),
-	})
+// repeatLabel returns the prefix of a listed reminder for its repeat interval.
+func repeatLabel(repeat models.RepeatInterval) string {
+	switch repeat {
+	case models.RepeatMinutely:
+		return "[Minutely] "
+	case models.RepeatHourly:
+		return "[Hourly] "
+	case models.RepeatDaily:
+		return "[Daily] "
+	case models.RepeatWeekly:
+		return "[Weekly] "
+	case models.RepeatWeekdays:
+		return "[Weekdays] "
+	case models.RepeatBusinessDays:
+		return "[Business days] "
+	}
+	return ""
+}
 
-	embed := &disgord.Embed{
-		Title:       fmt.Sprintf("Command \"%s\" usage", c.Name()),
-		Description: fmt.Sprintf("%s", cmd),
-		Color:       0xe5004c,
-		Fields:      fields,
+// formatSlot returns a short representation of a schedule slot depending on its repeat interval.
+func formatSlot(slot models.Reminder) string {
+	due, _ := goment.Unix(slot.Due)
+	switch slot.Repeat {
+	case models.NoRepeat:
+		return due.Format("Do MMMM HH:mm")
+	case models.RepeatWeekly:
+		return due.Format("ddd HH:mm")
 	}
-	s.SendEmbed(embed)
+	return due.Format("HH:mm")
+}
+
+func (*List) Usage() common.CommandUsage {
+	return common.CommandUsage{}
 }
@@ -0,0 +1,148 @@
+package permissions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+// Permissions role permission level mapping command.
+type Permissions struct{}
+
+func Init() *Permissions {
+	return &Permissions{}
+}
+
+func (*Permissions) Name() string {
+	return "permissions"
+}
+
+func (*Permissions) Aliases() []string {
+	return []string{"perms"}
+}
+
+func (*Permissions) Description() string {
+	return "Show your permission level or grant moderator and admin levels to roles."
+}
+
+func (*Permissions) Category() common.CommandCategory {
+	return common.CategorySettings
+}
+
+func (*Permissions) Permission() common.PermissionLevel {
+	return common.PermissionDefault
+}
+
+func (*Permissions) Active() bool {
+	return true
+}
+
+func (c *Permissions) Execute(s common.MessageState) {
+	if s.GuildID().Empty() {
+		s.Reply(fmt.Sprintf("Your permission level is %s.", s.UserPermission()))
+		return
+	}
+
+	action := s.Args.String("action")
+	if action == "" || action == "list" {
+		c.list(s)
+		return
+	}
+
+	if s.UserPermission() < common.PermissionAdmin {
+		s.Reply("Only server admins can grant permission levels to roles.")
+		return
+	}
+
+	if !s.Args.Has("role") {
+		s.Reply("Sorry, missing argument \"role\"!")
+		return
+	}
+	roleID := s.Args.Snowflake("role")
+
+	level := common.PermissionDefault
+	if action == "set" {
+		if !s.Args.Has("level") {
+			s.Reply("Sorry, missing argument \"level\"!")
+			return
+		}
+		level, _ = common.ParsePermissionLevel(s.Args.String("level"))
+	}
+
+	err := common.SetRoleLevel(s.GuildID(), roleID, level)
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	if level == common.PermissionDefault {
+		s.Reply(fmt.Sprintf("<@&%s> no longer grants a permission level.", roleID))
+		return
+	}
+	s.Reply(fmt.Sprintf("<@&%s> now grants the permission level %s.", roleID, level))
+}
+
+// list replies with the user's permission level and the roles granting levels.
+func (*Permissions) list(s common.MessageState) {
+	roles, err := common.GetRoleLevels(s.GuildID())
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	var lines []string
+	for _, role := range roles {
+		lines = append(lines, fmt.Sprintf("<@&%s>: %s", role.RoleID, common.PermissionLevel(role.Level)))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "No roles grant a permission level yet.")
+	}
+
+	s.SendEmbed(&disgord.Embed{
+		Title: "Permission levels",
+		Description: fmt.Sprintf(
+			"Your permission level is %s. Members who can manage the server are admins, "+
+				"members who can manage messages, kick or ban members are moderators.",
+			s.UserPermission(),
+		),
+		Color: 0xe5004c,
+		Fields: []*disgord.EmbedField{
+			{
+				Name:  "Roles",
+				Value: strings.Join(lines, "\n"),
+			},
+		},
+	})
+}
+
+func (*Permissions) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "action",
+				Description: "Grant a permission level to a role or remove it (server admins only)",
+				Optional:    true,
+				Choices:     []string{"list", "set", "remove"},
+			},
+			{
+				Name:        "role",
+				Description: "The role granting the permission level",
+				Type:        common.ArgumentRole,
+				Optional:    true,
+			},
+			{
+				Name:        "level",
+				Description: "The permission level granted by the role",
+				Optional:    true,
+				Choices:     []string{"moderator", "admin"},
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Showing your permission level and the roles granting levels"},
+			{Description: "Making a role moderators", Args: "set @Mods moderator"},
+			{Description: "Removing the level of a role", Args: "remove @Mods"},
+		},
+	}
+}
@@ -1,9 +1,8 @@
 package ping
 
 import (
-	"fmt"
+	"time"
 
-	"github.com/andersfylling/disgord"
 	"github.com/qysp/disgotify/pkg/common"
 )
 
@@ -26,6 +25,10 @@ func (*Ping) Description() string {
 	return "Test command. Send a ping, receive a pong."
 }
 
+func (*Ping) Category() common.CommandCategory {
+	return common.CategoryGeneral
+}
+
 func (*Ping) Permission() common.PermissionLevel {
 	return common.PermissionDefault
 }
@@ -34,16 +37,21 @@ func (*Ping) Active() bool {
 	return true
 }
 
+func (*Ping) Cooldowns() []common.Cooldown {
+	return []common.Cooldown{
+		{Scope: common.CooldownChannel, Uses: 5, Period: 10 * time.Second},
+	}
+}
+
 func (*Ping) Execute(s common.MessageState) {
 	s.Send("pong")
 }
 
-func (c *Ping) Help(s common.MessageState) {
-	cmd := common.CommandPrefix + c.Name()
+func (*Ping) Usage() common.CommandUsage {
 	// Unnecessary but I'll leave it as a template for upcomming commands.
-	s.SendEmbed(&disgord.Embed{
-		Title:       fmt.Sprintf("Command \"%s\" usage", c.Name()),
-		Description: fmt.Sprintf("%s", cmd),
-		Color:       0xe5004c,
-	})
+	return common.CommandUsage{
+		Examples: []common.UsageExample{
+			{Description: "Sending a ping"},
+		},
+	}
 }
@@ -0,0 +1,317 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+const (
+	// describeTimeout is the time a plugin has to describe itself on startup.
+	describeTimeout = 5 * time.Second
+	// maxOutput is the maximum size of a plugin's reply.
+	maxOutput = 1 << 20
+)
+
+// Description represents the reply of a plugin to `describe`.
+type Description struct {
+	Name        string                `json:"name"`
+	Aliases     []string              `json:"aliases"`
+	Description string                `json:"description"`
+	Permission  string                `json:"permission"`
+	Arguments   []ArgumentDesc        `json:"arguments"`
+	Examples    []common.UsageExample `json:"examples"`
+}
+
+// ArgumentDesc represents an argument declared by a plugin.
+type ArgumentDesc struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Type        string   `json:"type"`
+	Optional    bool     `json:"optional"`
+	Choices     []string `json:"choices"`
+}
+
+// Request represents the message context sent to a plugin on invocation.
+type Request struct {
+	Command   string            `json:"command"`
+	Args      common.Arguments  `json:"args"`
+	Content   string            `json:"content"`
+	MessageID disgord.Snowflake `json:"message_id"`
+	ChannelID disgord.Snowflake `json:"channel_id"`
+	GuildID   disgord.Snowflake `json:"guild_id"`
+	User      RequestUser       `json:"user"`
+}
+
+// RequestUser represents the user invoking a plugin.
+type RequestUser struct {
+	ID            disgord.Snowflake `json:"id"`
+	Username      string            `json:"username"`
+	Discriminator string            `json:"discriminator"`
+}
+
+// Response represents the reply of a plugin to an invocation.
+type Response struct {
+	Content string         `json:"content"`
+	Embed   *disgord.Embed `json:"embed"`
+}
+
+// Plugin command implemented by an executable in the plugin directory.
+type Plugin struct {
+	path        string
+	description Description
+}
+
+// Load describes all executables in dir and returns them as commands.
+// Plugins which cannot be described are logged and skipped.
+func Load(dir string) []*Plugin {
+	if dir == "" {
+		return nil
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		common.Logger.Error(err)
+		return nil
+	}
+
+	var plugins []*Plugin
+	for _, file := range files {
+		// Only regular, executable files are plugins.
+		if !file.Mode().IsRegular() || file.Mode().Perm()&0111 == 0 {
+			continue
+		}
+
+		p, err := describe(filepath.Join(dir, file.Name()))
+		if err != nil {
+			common.Logger.Error(fmt.Sprintf("Cannot load plugin %s: %s", file.Name(), err.Error()))
+			continue
+		}
+
+		plugins = append(plugins, p)
+		common.Logger.Info("Loaded plugin", p.Name(), "from", file.Name())
+	}
+	return plugins
+}
+
+// describe runs a plugin with `describe` and validates its description.
+func describe(path string) (*Plugin, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), describeTimeout)
+	defer cancel()
+
+	out, err := run(ctx, path, "describe", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Plugin{path: path}
+	if err := json.Unmarshal(out, &p.description); err != nil {
+		return nil, fmt.Errorf("malformed description: %s", err.Error())
+	}
+
+	d := &p.description
+	d.Name = strings.ToLower(strings.TrimSpace(d.Name))
+	if d.Name == "" || strings.ContainsAny(d.Name, " \t\n") {
+		return nil, fmt.Errorf("invalid name \"%s\"", d.Name)
+	}
+	if d.Permission != "" {
+		if _, err := common.ParsePermissionLevel(d.Permission); err != nil {
+			return nil, err
+		}
+	}
+	for _, arg := range d.Arguments {
+		if _, ok := argumentTypes[arg.Type]; !ok {
+			return nil, fmt.Errorf("unknown type \"%s\" of argument \"%s\"", arg.Type, arg.Name)
+		}
+	}
+
+	return p, nil
+}
+
+// run executes a plugin with an action, writes input to its stdin and returns its stdout.
+func run(ctx context.Context, path, action string, input []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(path, action)
+	cmd.Dir = filepath.Dir(path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &limitedBuffer{buf: &stdout, limit: maxOutput}
+	cmd.Stderr = &limitedBuffer{buf: &stderr, limit: maxOutput}
+	cmd.Env = pluginEnv()
+
+	if err := startGroup(cmd); err != nil {
+		return nil, err
+	}
+
+	// Wait may block until processes started by the plugin exit as well, so they are killed with it on timeout.
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		if err := killGroup(cmd); err != nil {
+			common.Logger.Error(err)
+		}
+		return nil, fmt.Errorf("timed out")
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %s", err.Error(), msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// pluginEnv returns the environment of plugins, which must not see the bot's token or other settings.
+func pluginEnv() []string {
+	env := []string{"DISGOTIFY_PLUGIN=1"}
+	for _, key := range []string{"PATH", "HOME", "LANG"} {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+	return env
+}
+
+// limitedBuffer represents a writer discarding everything beyond its limit.
+type limitedBuffer struct {
+	buf   *bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buf.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.buf.Write(p[:remaining])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (p *Plugin) Name() string {
+	return p.description.Name
+}
+
+func (p *Plugin) Aliases() []string {
+	return p.description.Aliases
+}
+
+func (p *Plugin) Description() string {
+	return p.description.Description
+}
+
+func (*Plugin) Category() common.CommandCategory {
+	return common.CategoryPlugins
+}
+
+func (p *Plugin) Permission() common.PermissionLevel {
+	level, _ := common.ParsePermissionLevel(p.description.Permission)
+	return level
+}
+
+func (*Plugin) Active() bool {
+	return true
+}
+
+// Execute sends the message context to the plugin and relays its reply.
+func (p *Plugin) Execute(s common.MessageState) {
+	author := s.Event.Message.Author
+	input, err := json.Marshal(Request{
+		Command:   p.Name(),
+		Args:      s.Args,
+		Content:   s.Message(),
+		MessageID: s.Event.Message.ID,
+		ChannelID: s.Event.Message.ChannelID,
+		GuildID:   s.GuildID(),
+		User: RequestUser{
+			ID:            author.ID,
+			Username:      author.Username,
+			Discriminator: author.Discriminator.String(),
+		},
+	})
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.Context(), s.Config.PluginTimeout)
+	defer cancel()
+
+	out, err := run(ctx, p.path, "run", input)
+	if err != nil {
+		common.Logger.Error(fmt.Sprintf("Plugin %s failed: %s", p.Name(), err.Error()))
+		s.Reply(fmt.Sprintf("Sorry, the command \"%s\" failed!", p.Name()))
+		return
+	}
+
+	var res Response
+	if err := json.Unmarshal(out, &res); err != nil {
+		common.Logger.Error(fmt.Sprintf("Plugin %s replied malformed output: %s", p.Name(), err.Error()))
+		s.Reply(fmt.Sprintf("Sorry, the command \"%s\" returned an invalid reply!", p.Name()))
+		return
+	}
+
+	if res.Content != "" {
+		s.Send(res.Content)
+	}
+	if res.Embed != nil {
+		s.SendPaginated(res.Embed)
+	}
+}
+
+// argumentTypes maps the argument types of plugin descriptions.
+var argumentTypes = map[string]common.ArgumentType{
+	"":        common.ArgumentString,
+	"string":  common.ArgumentString,
+	"integer": common.ArgumentInteger,
+	"user":    common.ArgumentUser,
+	"channel": common.ArgumentChannel,
+	"text":    common.ArgumentText,
+	"role":    common.ArgumentRole,
+}
+
+// Usage returns the declared arguments, plugins without arguments receive all words as "args".
+func (p *Plugin) Usage() common.CommandUsage {
+	usage := common.CommandUsage{
+		Examples: p.description.Examples,
+	}
+
+	if p.description.Arguments == nil {
+		usage.Arguments = []common.Argument{
+			{
+				Name:        "args",
+				Description: "Arguments passed to the plugin",
+				Type:        common.ArgumentText,
+				Optional:    true,
+			},
+		}
+		return usage
+	}
+
+	for _, arg := range p.description.Arguments {
+		usage.Arguments = append(usage.Arguments, common.Argument{
+			Name:        arg.Name,
+			Description: arg.Description,
+			Type:        argumentTypes[arg.Type],
+			Optional:    arg.Optional,
+			Choices:     arg.Choices,
+		})
+	}
+	return usage
+}
@@ -0,0 +1,141 @@
+package plugin
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+func TestMain(m *testing.M) {
+	common.InitNopLogger()
+	os.Exit(m.Run())
+}
+
+// writePlugin writes a shell script plugin into dir and returns its path.
+func writePlugin(t *testing.T, dir, name, script string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "disgotify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestLoad(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	writePlugin(t, dir, "dice", `echo '{"name": " Dice ", "aliases": ["roll"], "permission": "moderator", "arguments": [{"name": "sides", "type": "integer", "optional": true}, {"name": "role", "type": "role"}]}'`)
+	writePlugin(t, dir, "spaces", `echo '{"name": "two words"}'`)
+	writePlugin(t, dir, "type", `echo '{"name": "type", "arguments": [{"name": "x", "type": "float"}]}'`)
+	writePlugin(t, dir, "permission", `echo '{"name": "permission", "permission": "root"}'`)
+	writePlugin(t, dir, "malformed", `echo 'dice'`)
+	writePlugin(t, dir, "crash", `exit 1`)
+	if err := ioutil.WriteFile(filepath.Join(dir, "readme"), []byte(`{"name": "readme"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins := Load(dir)
+	if len(plugins) != 1 {
+		t.Fatalf("loaded %d plugins, want only dice", len(plugins))
+	}
+
+	p := plugins[0]
+	if p.Name() != "dice" || p.Aliases()[0] != "roll" || p.Permission() != common.PermissionModerator {
+		t.Errorf("got %+v", p.description)
+	}
+	args := p.Usage().Arguments
+	if len(args) != 2 || args[0].Type != common.ArgumentInteger || !args[0].Optional || args[1].Type != common.ArgumentRole {
+		t.Errorf("got arguments %+v", args)
+	}
+}
+
+func TestUsageWithoutArguments(t *testing.T) {
+	p := &Plugin{description: Description{Name: "echo"}}
+	args := p.Usage().Arguments
+	if len(args) != 1 || args[0].Name != "args" || args[0].Type != common.ArgumentText || !args[0].Optional {
+		t.Errorf("got %+v, want the optional text argument args", args)
+	}
+}
+
+func TestRunEnvironment(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	os.Setenv("DISCORD_TOKEN", "secret")
+	defer os.Unsetenv("DISCORD_TOKEN")
+
+	path := writePlugin(t, dir, "env", "env\n")
+	out, err := run(context.Background(), path, "run", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := string(out)
+	if strings.Contains(env, "DISCORD_TOKEN") {
+		t.Error("plugins must not see the token")
+	}
+	if !strings.Contains(env, "DISGOTIFY_PLUGIN=1") {
+		t.Errorf("DISGOTIFY_PLUGIN missing in %q", env)
+	}
+	if path, ok := os.LookupEnv("PATH"); ok && !strings.Contains(env, "PATH="+path) {
+		t.Errorf("PATH missing in %q", env)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	path := writePlugin(t, dir, "echo", `echo "$1"; cat`)
+	out, err := run(context.Background(), path, "run", []byte("input"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "run\ninput" {
+		t.Errorf("got %q", out)
+	}
+
+	path = writePlugin(t, dir, "fail", "echo broken >&2; exit 3")
+	if _, err := run(context.Background(), path, "run", nil); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("got %v, want the error including stderr", err)
+	}
+
+	path = writePlugin(t, dir, "slow", "sleep 5")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := run(ctx, path, "run", nil); err == nil || err.Error() != "timed out" {
+		t.Errorf("got %v, want timed out", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("run returned after %s, long after the timeout", elapsed)
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	path := writePlugin(t, dir, "flood", "head -c 2000000 /dev/zero")
+	out, err := run(context.Background(), path, "run", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != maxOutput {
+		t.Errorf("got %d bytes, want %d", len(out), maxOutput)
+	}
+}
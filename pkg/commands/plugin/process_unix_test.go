@@ -0,0 +1,57 @@
+//go:build linux
+// +build linux
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// running returns whether a process exists and isn't a zombie.
+func running(pid int) bool {
+	stat, err := ioutil.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	// The state follows the parenthesized command name.
+	fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+func TestRunKillsProcessGroup(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	// The child keeps stdout open, so waiting for the plugin alone would block until it exits.
+	pidFile := filepath.Join(dir, "child.pid")
+	path := writePlugin(t, dir, "spawn", "sleep 30 &\necho $! > "+pidFile+"\nwait")
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := run(ctx, path, "run", nil); err == nil || err.Error() != "timed out" {
+		t.Fatalf("got %v, want timed out", err)
+	}
+
+	content, err := ioutil.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for running(pid) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if running(pid) {
+		t.Errorf("the process %d started by the plugin must be killed", pid)
+	}
+}
@@ -0,0 +1,15 @@
+package plugin
+
+import (
+	"os/exec"
+)
+
+// startGroup starts the plugin, Windows has no process groups which could be killed at once.
+func startGroup(cmd *exec.Cmd) error {
+	return cmd.Start()
+}
+
+// killGroup kills the plugin, processes it started keep running.
+func killGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,124 @@
+package prefix
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+// maxPrefixLength is the maximum length of a guild's command prefix.
+const maxPrefixLength = 10
+
+// Prefix command prefix selection command.
+type Prefix struct{}
+
+func Init() *Prefix {
+	return &Prefix{}
+}
+
+func (*Prefix) Name() string {
+	return "prefix"
+}
+
+func (*Prefix) Aliases() []string {
+	return []string{}
+}
+
+func (*Prefix) Description() string {
+	return "Show or change the command prefix used in this server."
+}
+
+func (*Prefix) Category() common.CommandCategory {
+	return common.CategorySettings
+}
+
+func (*Prefix) Permission() common.PermissionLevel {
+	return common.PermissionDefault
+}
+
+func (*Prefix) Active() bool {
+	return true
+}
+
+func (*Prefix) Execute(s common.MessageState) {
+	if !s.Args.Has("action") {
+		s.Reply(fmt.Sprintf("The command prefix in here is `%s`.", s.GuildPrefix()))
+		return
+	}
+
+	if s.GuildID().Empty() {
+		s.Reply("A command prefix can only be set in a server.")
+		return
+	}
+
+	if s.UserPermission() < common.PermissionAdmin {
+		s.Reply("Only server admins can change the command prefix.")
+		return
+	}
+
+	prefix := ""
+	if s.Args.String("action") == "set" {
+		if !s.Args.Has("prefix") {
+			s.Reply("Sorry, missing argument \"prefix\"!")
+			return
+		}
+		prefix = s.Args.String("prefix")
+		if err := validatePrefix(prefix); err != nil {
+			s.Reply(fmt.Sprintf("Sorry, %s!", err.Error()))
+			return
+		}
+	}
+
+	settings, err := common.GetGuildSettings(s.GuildID())
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	settings.Prefix = prefix
+	err = common.SaveGuildSettings(settings)
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	s.Reply(fmt.Sprintf("The command prefix in this server is now `%s`.", s.GuildPrefix()))
+}
+
+// validatePrefix rejects prefixes which cannot be typed reliably or would be mistaken for mentions.
+func validatePrefix(prefix string) error {
+	if len(prefix) > maxPrefixLength {
+		return fmt.Errorf("the prefix can't be longer than %d characters", maxPrefixLength)
+	}
+	if strings.ContainsAny(prefix, "`\\") {
+		return fmt.Errorf("the prefix can't contain backticks or backslashes")
+	}
+	if strings.HasPrefix(prefix, "<") {
+		return fmt.Errorf("the prefix can't start with \"<\"")
+	}
+	return nil
+}
+
+func (*Prefix) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "action",
+				Description: "Change the prefix or reset it to the default (server admins only)",
+				Optional:    true,
+				Choices:     []string{"set", "reset"},
+			},
+			{
+				Name:        "prefix",
+				Description: "The new command prefix, e.g. \"!\"",
+				Optional:    true,
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Showing the current prefix"},
+			{Description: "Changing the prefix (server admins only)", Args: "set !"},
+			{Description: "Resetting the prefix to the default", Args: "reset"},
+		},
+	}
+}
@@ -0,0 +1,25 @@
+package prefix
+
+import "testing"
+
+func TestValidatePrefix(t *testing.T) {
+	tests := []struct {
+		prefix string
+		valid  bool
+	}{
+		{"!", true},
+		{"", true},
+		{"disgotify.", true},
+		{"disgotify!!", false},
+		{"`", false},
+		{"\\", false},
+		{"<@", false},
+		{"a<", true},
+	}
+
+	for _, test := range tests {
+		if err := validatePrefix(test.prefix); (err == nil) != test.valid {
+			t.Errorf("validatePrefix(%q) = %v, want valid %v", test.prefix, err, test.valid)
+		}
+	}
+}
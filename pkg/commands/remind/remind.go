@@ -6,17 +6,47 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/andersfylling/disgord"
+	"github.com/jinzhu/gorm"
 	"github.com/nleeper/goment"
 	"github.com/qysp/disgotify/pkg/common"
 	"github.com/qysp/disgotify/pkg/models"
+	"github.com/qysp/disgotify/pkg/services/reminderservice"
 )
 
 var repeatIntervalTranslate = map[string]models.RepeatInterval{
-	"minutely": models.RepeatMinutely,
-	"hourly":   models.RepeatHourly,
-	"daily":    models.RepeatDaily,
+	"minutely":     models.RepeatMinutely,
+	"hourly":       models.RepeatHourly,
+	"daily":        models.RepeatDaily,
+	"weekly":       models.RepeatWeekly,
+	"weekdays":     models.RepeatWeekdays,
+	"businessdays": models.RepeatBusinessDays,
+}
+
+var weekdays = map[string]int{
+	// Long, short and minimal representation of weekdays.
+	"monday":    1,
+	"mon":       1,
+	"mo":        1,
+	"tuesday":   2,
+	"tue":       2,
+	"tu":        2,
+	"wednesday": 3,
+	"wed":       3,
+	"we":        3,
+	"thursday":  4,
+	"thu":       4,
+	"th":        4,
+	"friday":    5,
+	"fri":       5,
+	"fr":        5,
+	"saturday":  6,
+	"sat":       6,
+	"sa":        6,
+	"sunday":    7,
+	"sun":       7,
+	"su":        7,
 }
 
 // Remind reminder command.
@@ -38,6 +68,10 @@ func (*Remind) Description() string {
 	return "Register a reminder for specific date and time and receive a notification."
 }
 
+func (*Remind) Category() common.CommandCategory {
+	return common.CategoryReminders
+}
+
 func (*Remind) Permission() common.PermissionLevel {
 	return common.PermissionDefault
 }
@@ -46,186 +80,229 @@ func (*Remind) Active() bool {
 	return true
 }
 
-func (c *Remind) Execute(s common.MessageState) {
-	if len(s.UserCommandArgs()) < 2 {
-		c.Help(s)
-		return
+func (*Remind) Cooldowns() []common.Cooldown {
+	return []common.Cooldown{
+		{Scope: common.CooldownUser, Uses: 5, Period: time.Minute},
+		{Scope: common.CooldownGuild, Uses: 30, Period: time.Minute},
 	}
+}
 
-	// Need an unaltered version of command arguments for the notification.
-	userCmdArgs := s.UserCommandArgs()[2:]
+func (c *Remind) Execute(s common.MessageState) {
+	sched := s.Args.Value("date").(*schedule)
+	interval := sched.interval
 
-	var cmdArgs []string
-	for _, arg := range s.UserCommandArgs() {
-		if arg != "" {
-			cmdArgs = append(cmdArgs, strings.ToLower(arg))
-		}
-	}
+	calendar := common.GuildHolidayCalendar(s.GuildID(), s.Config.DefaultHolidayCalendar)
 
-	interval, hasRepeat := repeatIntervalTranslate[cmdArgs[0]]
-	hasNext := cmdArgs[0] == "next"
+	// Multiple dates and times are separated by commas, every combination is a schedule slot.
+	dates := sched.dates
+	times := strings.Split(strings.ToLower(s.Args.String("time")), ",")
 
-	// If the "next" keywords is given we need to shift the arguments.
-	if hasNext {
-		cmdArgs = cmdArgs[1:]
-		userCmdArgs = userCmdArgs[1:]
+	// A list of weekdays (e.g. mon,wed,fri) repeats every week.
+	if !sched.hasRepeat && len(dates) > 1 && allWeekdays(dates) {
+		interval = models.RepeatWeekly
 	}
 
-	gDate, err := parseDate(cmdArgs, hasNext, hasRepeat)
-	if err != nil {
-		s.Reply(fmt.Sprintf("Sorry, %s!", err.Error()))
-		return
+	var slots []*goment.Goment
+	for _, date := range dates {
+		for _, time := range times {
+			g, err := parseSlot(date, time, sched.hasNext, sched.hasRepeat, interval, calendar, s.Config.ReminderInterval)
+			if err != nil {
+				s.Reply(fmt.Sprintf("Sorry, %s!", err.Error()))
+				return
+			}
+			slots = append(slots, g)
+		}
 	}
 
-	gTime, err := parseTime(cmdArgs)
+	// All slots of a reminder are stored in a transaction, so it's never registered partially.
+	err := common.Transaction(s.Context(), func(tx *gorm.DB) error {
+		var groupID uint
+		for _, g := range slots {
+			reminder := &models.Reminder{
+				UserID:       s.UserID(),
+				GuildID:      s.GuildID(),
+				Due:          g.ToUnix(),
+				Notification: s.Args.String("notification"),
+				Repeat:       interval,
+				GroupID:      groupID,
+			}
+			if interval == models.RepeatBusinessDays {
+				reminder.Calendar = calendar
+			}
+
+			if err := tx.Create(reminder).Error; err != nil {
+				return err
+			}
+			if len(slots) > 1 && groupID == 0 {
+				// The first slot's ID identifies the whole group.
+				groupID = reminder.ID
+				if err := tx.Model(reminder).Update("group_id", groupID).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		s.Reply(fmt.Sprintf("Sorry, %s!", err.Error()))
+		s.Fail(err)
 		return
 	}
 
-	dateTime := gDate.Format("YYYY-MM-DD") + " " + gTime.Format("HH:mm:ss")
-
-	// Using local timezone.
-	g, _ := goment.New(dateTime, "YYYY-MM-DD HH:mm:ss")
+	if err := common.RecordReminders(int64(len(slots)), 0); err != nil {
+		s.Session.Logger().Error(err)
+	}
 
-	// Only add reminders that lay at least `ReminderInterval` seconds in the future.
-	if now, _ := goment.New(); now.Diff(g) > -int(common.ReminderInterval.Seconds()) {
-		// If the user wants to add a daily reminder, register it for the next day.
-		if interval == models.RepeatDaily {
-			g.Add(1, "day")
-		} else {
-			s.Reply("Reminder must be (father) in the future.")
-			return
+	next := slots[0]
+	for _, g := range slots[1:] {
+		if g.IsBefore(next) {
+			next = g
 		}
 	}
 
-	err = common.DB.Create(&models.Reminder{
-		UserID:       s.UserID(),
-		Due:          g.ToUnix(),
-		Notification: strings.Join(userCmdArgs, " "),
-		Repeat:       interval,
-	}).Error
-
-	if err != nil {
-		s.Session.Logger().Error(err)
-		s.Reply(fmt.Sprintf("Unexpected error: %s", err.Error()))
+	if len(slots) > 1 {
+		s.Reply(fmt.Sprintf("I will remind you %s (%d schedule slots).", next.FromNow(), len(slots)))
 		return
 	}
-
-	s.Reply(fmt.Sprintf("I will remind you %s.", g.FromNow()))
+	s.Reply(fmt.Sprintf("I will remind you %s.", next.FromNow()))
 }
 
-func (c *Remind) Help(s common.MessageState) {
-	cmd := common.CommandPrefix + c.Name()
-	fields := []*disgord.EmbedField{}
+func (*Remind) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "date",
+				Description: "Date or repeat keyword, optionally preceded by \"next\"",
+				Parse:       parseSchedule,
+			},
+			{
+				Name:        "time",
+				Description: "HH:mm:ss, HH.mm.ss (both 24 and 12 hour with am/pm supported)",
+			},
+			{
+				Name:        "notification",
+				Description: "Literally anything you want, e.g. some words and a :thinking: emoji",
+				Type:        common.ArgumentText,
+				Optional:    true,
+			},
+		},
+		Notes: []common.UsageNote{
+			{
+				Name:  "Available 'repeat' keywords",
+				Value: "minutely, hourly, daily, weekly, weekdays, businessdays (weekdays without holidays)",
+			},
+			{Name: "[Date] Aliases: today", Value: "t, now"},
+			{Name: "[Date] Aliases: tomorrow", Value: "tmr, tr"},
+			{
+				Name:  "[Date] Next business day",
+				Value: "next business day (skips weekends and holidays of the selected holiday calendar)",
+			},
+			{Name: "[Date] Allowed weekday formats", Value: "Mo-Su, Mon-Sun, Monday-Sunday"},
+			{Name: "[Date] Allowed date formats", Value: "DD/MM/YYYY, DD-MM-YYYY, DD.MM.YYYY"},
+			{
+				Name:  "[Date/Time] Multiple dates and times",
+				Value: "Separate them with commas, a list of weekdays repeats weekly",
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Adding a reminder for today", Args: "today 11am walk the dog"},
+			{Description: "Adding a reminder for next thursday", Args: "next thursday 16:00 doctor's appointment"},
+			{Description: "Adding a reminder for every business day", Args: "businessdays 9:30 standup"},
+			{Description: "Adding a reminder for several days of every week", Args: "mon,wed,fri 7am gym"},
+			{Description: "Adding a reminder for several times of every day", Args: "daily 9am,1pm,6pm meds"},
+			{Description: "Adding a reminder for a specific date", Args: "31.12 6pm party @ joes"},
+		},
+	}
+}
 
-	// Command aliases.
-	fields = append(fields, &disgord.EmbedField{
-		Name:  "Aliases",
-		Value: strings.Join(c.Aliases(), ", "),
-	})
+// schedule represents the parsed date argument of the remind command.
+type schedule struct {
+	dates     []string
+	hasNext   bool
+	hasRepeat bool
+	interval  models.RepeatInterval
+}
 
-	// Available repeat keywords.
-	fields = append(fields, &disgord.EmbedField{
-		Name:  "Available 'repeat' keywords",
-		Value: "minutely, hourly, daily",
-	})
+// parseSchedule parses the date argument, which may consist of multiple words (e.g. "next business day").
+func parseSchedule(args []string) (interface{}, int, error) {
+	// Remember the position of every word to know how many arguments were consumed.
+	var words []string
+	var positions []int
+	for i, arg := range args {
+		if arg != "" {
+			words = append(words, strings.ToLower(arg))
+			positions = append(positions, i)
+		}
+	}
 
-	// Aliases for "today".
-	fields = append(fields, &disgord.EmbedField{
-		Name:  "[Date] Aliases: today",
-		Value: "t, now",
-	})
+	sched := &schedule{}
+	sched.interval, sched.hasRepeat = repeatIntervalTranslate[words[0]]
 
-	// Aliases for "tomorrow".
-	fields = append(fields, &disgord.EmbedField{
-		Name:  "[Date] Aliases: tomorrow",
-		Value: "tmr, tr",
-	})
+	n := 0
+	// If the "next" keywords is given we need to shift the arguments.
+	if words[n] == "next" && len(words) > 1 {
+		sched.hasNext = true
+		n++
+	}
 
-	// Allowed weekday formats.
-	fields = append(fields, &disgord.EmbedField{
-		Name:  "[Date] Allowed weekday formats",
-		Value: "Mo-Su, Mon-Sun, Monday-Sunday",
-	})
+	date := words[n]
+	// "business day" is the only date consisting of two words, merge them.
+	if date == "business" && len(words) > n+1 && words[n+1] == "day" {
+		date = "businessday"
+		n++
+	}
 
-	// Allowed date formats.
-	fields = append(fields, &disgord.EmbedField{
-		Name:  "[Date] Allowed date formats",
-		Value: "DD/MM/YYYY, DD-MM-YYYY, DD.MM.YYYY",
-	})
+	sched.dates = strings.Split(date, ",")
+	return sched, positions[n] + 1, nil
+}
 
-	// Allowed time formats.
-	fields = append(fields, &disgord.EmbedField{
-		Name:  "[Time] Allowed time formats",
-		Value: "HH:mm:ss, HH.mm.ss (both 24 and 12 hour with am/pm supported)",
-	})
+// parseSlot parses a single date and time combination and returns its first due date,
+// which lies at least one reminder interval in the future.
+func parseSlot(
+	date, time string,
+	hasNext, hasRepeat bool,
+	interval models.RepeatInterval,
+	calendar string,
+	reminderInterval time.Duration,
+) (*goment.Goment, error) {
+	gDate, err := parseDate(date, hasNext, hasRepeat, calendar)
+	if err != nil {
+		return nil, err
+	}
 
-	// Notification message.
-	fields = append(fields, &disgord.EmbedField{
-		Name:  "[Notification] Optional, literally anything you want",
-		Value: "Example: some words and a :thinking: emoji",
-	})
+	gTime, err := parseTime(time)
+	if err != nil {
+		return nil, err
+	}
 
-	// Usage example.
-	fields = append(fields, &disgord.EmbedField{
-		Name:  "[Example] Adding a reminder for today",
-		Value: fmt.Sprintf("%s today 11am walk the dog", cmd),
-	})
+	dateTime := gDate.Format("YYYY-MM-DD") + " " + gTime.Format("HH:mm:ss")
 
-	// Usage example.
-	fields = append(fields, &disgord.EmbedField{
-		Name:  "[Example] Adding a reminder for next thursday",
-		Value: fmt.Sprintf("%s next thursday 16:00 doctor's appointment", cmd),
-	})
+	// Using local timezone.
+	g, _ := goment.New(dateTime, "YYYY-MM-DD HH:mm:ss")
 
-	// Usage example.
-	fields = append(fields, &disgord.EmbedField{
-		Name:  "[Example] Adding a reminder for a specific date",
-		Value: fmt.Sprintf("%s 31.12 6pm party @ joes", cmd),
-	})
+	// Only add reminders that lay at least `reminderInterval` seconds in the future.
+	if now, _ := goment.New(); now.Diff(g) > -int(reminderInterval.Seconds()) {
+		switch interval {
+		case models.RepeatDaily, models.RepeatWeekly, models.RepeatWeekdays, models.RepeatBusinessDays:
+			// If the user wants to add a repeating reminder, register it for the next occurrence.
+			g, _ = goment.Unix(reminderservice.NextDue(g.ToUnix(), interval, calendar))
+		default:
+			return nil, errors.New("reminder must be (father) in the future")
+		}
+	} else if interval == models.RepeatWeekdays && !common.IsWeekday(g.ToTime()) ||
+		interval == models.RepeatBusinessDays && !common.IsBusinessDay(calendar, g.ToTime()) {
+		// Today is not a valid day for this repeat interval.
+		g, _ = goment.Unix(reminderservice.NextDue(g.ToUnix(), interval, calendar))
+	}
 
-	s.SendEmbed(&disgord.Embed{
-		Title:       fmt.Sprintf("Command \"%s\" usage", c.Name()),
-		Description: fmt.Sprintf("%s [date] [time] [notification?]", cmd),
-		Color:       0xe5004c,
-		Fields:      fields,
-	})
+	return g, nil
 }
 
 // Parse the user's date input.
-func parseDate(cmdArgs []string, hasNext bool, hasRepeat bool) (*goment.Goment, error) {
-	weekdays := map[string]int{
-		// Long, short and minimal representation of weekdays.
-		"monday":    1,
-		"mon":       1,
-		"mo":        1,
-		"tuesday":   2,
-		"tue":       2,
-		"tu":        2,
-		"wednesday": 3,
-		"wed":       3,
-		"we":        3,
-		"thursday":  4,
-		"thu":       4,
-		"th":        4,
-		"friday":    5,
-		"fri":       5,
-		"fr":        5,
-		"saturday":  6,
-		"sat":       6,
-		"sa":        6,
-		"sunday":    7,
-		"sun":       7,
-		"su":        7,
-	}
-
+func parseDate(date string, hasNext bool, hasRepeat bool, calendar string) (*goment.Goment, error) {
 	// Aliases for today/tomorrow.
 	todayAliases := []string{"today", "t", "now"}
 	tomorrowAliases := []string{"tomorrow", "tmr", "tr"}
 
-	date := cmdArgs[0]
-
 	g, _ := goment.New()
 
 	if hasRepeat || contains(todayAliases, date) {
@@ -237,6 +314,13 @@ func parseDate(cmdArgs []string, hasNext bool, hasRepeat bool) (*goment.Goment,
 		return g, nil
 	}
 
+	if date == "businessday" {
+		if !hasNext && common.IsBusinessDay(calendar, g.ToTime()) {
+			return g, nil
+		}
+		return goment.New(common.NextBusinessDay(calendar, g.ToTime()))
+	}
+
 	if weekday, ok := weekdays[date]; ok {
 		currentWeekday := g.ISOWeekday()
 		diff := (weekday - currentWeekday)
@@ -286,11 +370,9 @@ func parseDate(cmdArgs []string, hasNext bool, hasRepeat bool) (*goment.Goment,
 }
 
 // Parse the user's time input.
-func parseTime(cmdArgs []string) (*goment.Goment, error) {
+func parseTime(time string) (*goment.Goment, error) {
 	g, _ := goment.New()
 
-	time := cmdArgs[1]
-
 	// Whether it's necessary to add 12 hours to the time (goment expects a 24 hour format).
 	hasPM := regexp.MustCompile(`(?i)pm`).MatchString(time)
 	// Cleanup the time input.
@@ -345,6 +427,16 @@ func parseTime(cmdArgs []string) (*goment.Goment, error) {
 	return g, nil
 }
 
+// allWeekdays returns a bool which indicates whether every date is a weekday.
+func allWeekdays(dates []string) bool {
+	for _, date := range dates {
+		if _, ok := weekdays[date]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
 // Check if a string array contains a matching string.
 func contains(arr []string, str string) bool {
 	for _, el := range arr {
@@ -0,0 +1,111 @@
+package remind
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		args     []string
+		dates    []string
+		hasNext  bool
+		repeat   bool
+		consumed int
+	}{
+		{[]string{"today", "11am", "walk", "the", "dog"}, []string{"today"}, false, false, 1},
+		{[]string{"next", "thursday", "16:00"}, []string{"thursday"}, true, false, 2},
+		{[]string{"Mon,Wed,Fri", "7am", "gym"}, []string{"mon", "wed", "fri"}, false, false, 1},
+		{[]string{"next", "business", "day", "9am"}, []string{"businessday"}, true, false, 3},
+		{[]string{"daily", "9am,1pm"}, []string{"daily"}, false, true, 1},
+		// Empty arguments are left by multiple spaces between words.
+		{[]string{"next", "", "friday", "9am"}, []string{"friday"}, true, false, 3},
+	}
+
+	for _, test := range tests {
+		value, consumed, err := parseSchedule(test.args)
+		if err != nil {
+			t.Errorf("parseSchedule(%q) returned error %v", test.args, err)
+			continue
+		}
+		sched := value.(*schedule)
+		if !reflect.DeepEqual(sched.dates, test.dates) || sched.hasNext != test.hasNext || sched.hasRepeat != test.repeat || consumed != test.consumed {
+			t.Errorf("parseSchedule(%q) = %+v, %d", test.args, sched, consumed)
+		}
+	}
+}
+
+func TestParseSlotWeekdays(t *testing.T) {
+	now := time.Now()
+	for _, date := range []string{"mon", "wed", "fri"} {
+		g, err := parseSlot(date, "7am", false, false, models.RepeatWeekly, "", 10*time.Second)
+		if err != nil {
+			t.Fatalf("parseSlot(%s) returned error %v", date, err)
+		}
+		due := g.ToTime()
+		if due.Weekday() != time.Weekday(weekdays[date]%7) || due.Hour() != 7 {
+			t.Errorf("parseSlot(%s) = %s", date, due)
+		}
+		if !due.After(now) || due.After(now.AddDate(0, 0, 8)) {
+			t.Errorf("parseSlot(%s) = %s is not within the next week", date, due)
+		}
+	}
+}
+
+func TestParseSlotPast(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	if past.Day() != time.Now().Day() {
+		t.Skip("an hour ago was yesterday")
+	}
+	clock := past.Format("15:04")
+
+	if _, err := parseSlot("today", clock, false, false, models.NoRepeat, "", 10*time.Second); err == nil {
+		t.Error("a reminder in the past must be rejected")
+	}
+
+	g, err := parseSlot("daily", clock, false, true, models.RepeatDaily, "", 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if due := g.ToTime(); due.Before(time.Now()) || due.Day() == past.Day() {
+		t.Errorf("a daily reminder in the past must be moved to tomorrow, got %s", due)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		input                string
+		hour, minute, second int
+		valid                bool
+	}{
+		{"13:37", 13, 37, 0, true},
+		{"4.20pm", 16, 20, 0, true},
+		{"7am", 7, 0, 0, true},
+		{"23:59:30", 23, 59, 30, true},
+		{"24:00", 0, 0, 0, false},
+		{"noon", 0, 0, 0, false},
+	}
+
+	for _, test := range tests {
+		g, err := parseTime(test.input)
+		if (err == nil) != test.valid {
+			t.Errorf("parseTime(%s) error = %v, want valid %v", test.input, err, test.valid)
+			continue
+		}
+		if err == nil && (g.Hour() != test.hour || g.Minute() != test.minute || g.Second() != test.second) {
+			t.Errorf("parseTime(%s) = %s", test.input, g.Format("HH:mm:ss"))
+		}
+	}
+}
+
+func TestAllWeekdays(t *testing.T) {
+	if !allWeekdays([]string{"mon", "wednesday", "fr"}) {
+		t.Error("all dates are weekdays")
+	}
+	if allWeekdays([]string{"mon", "tomorrow"}) {
+		t.Error("tomorrow is not a weekday")
+	}
+}
@@ -2,10 +2,9 @@ package remove
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
+	"time"
 
-	"github.com/andersfylling/disgord"
+	"github.com/jinzhu/gorm"
 	"github.com/qysp/disgotify/pkg/common"
 	"github.com/qysp/disgotify/pkg/models"
 )
@@ -29,6 +28,10 @@ func (*Remove) Description() string {
 	return "Remove a reminder of yours."
 }
 
+func (*Remove) Category() common.CommandCategory {
+	return common.CategoryReminders
+}
+
 func (*Remove) Permission() common.PermissionLevel {
 	return common.PermissionDefault
 }
@@ -37,69 +40,70 @@ func (*Remove) Active() bool {
 	return true
 }
 
+func (*Remove) Cooldowns() []common.Cooldown {
+	return []common.Cooldown{
+		{Scope: common.CooldownUser, Uses: 10, Period: time.Minute},
+	}
+}
+
 func (*Remove) Execute(s common.MessageState) {
 	var reminders []models.Reminder
-	common.DB.Where(models.Reminder{
-		UserID: s.UserID(),
-	}).Find(&reminders)
+	err := common.Transaction(s.Context(), func(tx *gorm.DB) error {
+		return tx.Where(models.Reminder{
+			UserID: s.UserID(),
+		}).Find(&reminders).Error
+	})
+	if err != nil {
+		s.Fail(err)
+		return
+	}
 
-	if len(reminders) == 0 {
+	// Schedule slots of a reminder are removed together.
+	groups := models.GroupReminders(reminders)
+	if len(groups) == 0 {
 		s.Reply("You currently don't have any reminders registered.")
 		return
 	}
 
-	idx := len(reminders)
-	if len(s.UserCommandArgs()) != 0 {
-		// Parse uint, ensure it's not a negative index.
-		userIdx, err := strconv.ParseUint(s.UserCommandArgs()[0], 10, 32)
-		if err != nil {
-			s.Reply("Invalid reminder index.")
-			return
-		}
-		idx = int(userIdx)
+	idx := len(groups)
+	if s.Args.Has("index") {
+		idx = int(s.Args.Int("index"))
 	}
 
-	if idx > len(reminders) {
+	if idx < 1 || idx > len(groups) {
 		s.Reply("The reminder you're trying to remove does not exist.")
 		return
 	}
 
-	err := common.DB.Unscoped().Delete(&reminders[idx-1]).Error
+	var ids []uint
+	for _, reminder := range groups[idx-1] {
+		ids = append(ids, reminder.ID)
+	}
+
+	err = common.Transaction(s.Context(), func(tx *gorm.DB) error {
+		return tx.Unscoped().Where("id IN (?)", ids).Delete(&models.Reminder{}).Error
+	})
 	if err != nil {
-		s.Session.Logger().Error(err)
-		s.Reply(fmt.Sprintf("Unexpected error: %s", err.Error()))
+		s.Fail(err)
 		return
 	}
 
 	s.Reply(fmt.Sprintf("Deleted reminder #%d.", idx))
 }
 
-func (c *Remove) Help(s common.MessageState) {
-	cmd := common.CommandPrefix + c.Name()
-	fields := []*disgord.EmbedField{}
-
-	// Command aliases.
-	fields = append(fields, &disgord.EmbedField{
-		Name:  "Aliases",
-		Value: strings.Join(c.Aliases(), ", "),
-	})
-
-	// Usage example.
-	fields = append(fields, &disgord.EmbedField{
-		Name:  "[Example] Removing reminder #3",
-		Value: fmt.Sprintf("%s 3", cmd),
-	})
-
-	// Usage example.
-	fields = append(fields, &disgord.EmbedField{
-		Name:  "[Example] Removing the most recently added reminder",
-		Value: fmt.Sprintf("%s", cmd),
-	})
-
-	s.SendEmbed(&disgord.Embed{
-		Title:       fmt.Sprintf("Command \"%s\" usage", c.Name()),
-		Description: fmt.Sprintf("%s [reminder index?]", cmd),
-		Color:       0xe5004c,
-		Fields:      fields,
-	})
+func (*Remove) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "index",
+				Description: "Index of the reminder as shown by the list command, defaults to the most recently added reminder",
+				Type:        common.ArgumentInteger,
+				Optional:    true,
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Removing reminder #3", Args: "3"},
+			{Description: "Removing the most recently added reminder"},
+		},
+	}
 }
@@ -0,0 +1,259 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/common"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+const (
+	// topCommands is the number of commands listed.
+	topCommands = 10
+	// daysPerField is the number of days listed per field of the reminder statistics.
+	daysPerField = 14
+)
+
+// periods maps the selectable periods to their number of days, 0 means all time.
+var periods = map[string]int{
+	"today": 1,
+	"week":  7,
+	"month": 30,
+	"all":   0,
+}
+
+// Stats command usage statistics command.
+type Stats struct{}
+
+func Init() *Stats {
+	return &Stats{}
+}
+
+func (*Stats) Name() string {
+	return "stats"
+}
+
+func (*Stats) Aliases() []string {
+	return []string{"statistics", "analytics"}
+}
+
+func (*Stats) Description() string {
+	return "Show command usage and reminder statistics."
+}
+
+func (*Stats) Category() common.CommandCategory {
+	return common.CategoryGeneral
+}
+
+func (*Stats) Permission() common.PermissionLevel {
+	return common.PermissionDeveloper
+}
+
+func (*Stats) Active() bool {
+	return true
+}
+
+// Timeout is longer than the default, since all statistics of the period are aggregated.
+func (*Stats) Timeout() time.Duration {
+	return time.Minute
+}
+
+// commandTotal represents the statistics of a command summed up over the period.
+type commandTotal struct {
+	name       string
+	uses       int64
+	errors     int64
+	rejections int64
+	duration   int64
+}
+
+func (*Stats) Execute(s common.MessageState) {
+	period := s.Args.String("period")
+	if period == "" {
+		period = "week"
+	}
+
+	since := time.Unix(0, 0)
+	if days := periods[period]; days > 0 {
+		since = time.Now().AddDate(0, 0, 1-days)
+	}
+
+	commandStats, err := common.GetCommandStats(s.Context(), since)
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	reminderStats, err := common.GetReminderStats(s.Context(), since)
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	if len(commandStats) == 0 && len(reminderStats) == 0 {
+		s.Reply("There are no statistics for this period yet.")
+		return
+	}
+
+	var fields []*disgord.EmbedField
+	fields = append(fields, commandFields(commandStats)...)
+	fields = append(fields, reminderFields(reminderStats)...)
+	if common.CommandQueue != nil {
+		fields = append(fields, queueField(common.CommandQueue.Metrics()))
+	}
+
+	s.SendPaginated(&disgord.Embed{
+		Title:       fmt.Sprintf("Usage statistics (%s)", period),
+		Description: summary(commandStats),
+		Color:       0xe5004c,
+		Fields:      fields,
+	})
+}
+
+// summary returns the overall numbers of invocations, users and servers.
+func summary(stats []models.CommandStat) string {
+	var uses, errors, rejections int64
+	users := map[disgord.Snowflake]bool{}
+	guilds := map[disgord.Snowflake]bool{}
+	for _, stat := range stats {
+		uses += stat.Uses
+		errors += stat.Errors
+		rejections += stat.Rejections
+		users[stat.UserID] = true
+		if !stat.GuildID.Empty() {
+			guilds[stat.GuildID] = true
+		}
+	}
+
+	return fmt.Sprintf(
+		"%d invocations by %d active users in %d servers, %s failed and %s were rejected.",
+		uses,
+		len(users),
+		len(guilds),
+		percentage(errors, uses),
+		percentage(rejections, uses),
+	)
+}
+
+// commandFields returns the most used commands with their error rates and average durations.
+func commandFields(stats []models.CommandStat) []*disgord.EmbedField {
+	totals := map[string]*commandTotal{}
+	for _, stat := range stats {
+		total, ok := totals[stat.Command]
+		if !ok {
+			total = &commandTotal{name: stat.Command}
+			totals[stat.Command] = total
+		}
+		total.uses += stat.Uses
+		total.errors += stat.Errors
+		total.rejections += stat.Rejections
+		total.duration += stat.Duration
+	}
+	if len(totals) == 0 {
+		return nil
+	}
+
+	var sorted []*commandTotal
+	for _, total := range totals {
+		sorted = append(sorted, total)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].uses != sorted[j].uses {
+			return sorted[i].uses > sorted[j].uses
+		}
+		return sorted[i].name < sorted[j].name
+	})
+	if len(sorted) > topCommands {
+		sorted = sorted[:topCommands]
+	}
+
+	var lines []string
+	for _, total := range sorted {
+		lines = append(lines, fmt.Sprintf(
+			"`%s` %d uses, %s errors, %s rejected, %dms on average",
+			total.name,
+			total.uses,
+			percentage(total.errors, total.uses),
+			percentage(total.rejections, total.uses),
+			total.duration/total.uses,
+		))
+	}
+
+	return []*disgord.EmbedField{
+		{
+			Name:  "Top commands",
+			Value: strings.Join(lines, "\n"),
+		},
+	}
+}
+
+// reminderFields returns the reminders created and delivered per day.
+func reminderFields(stats []models.ReminderStat) []*disgord.EmbedField {
+	var fields []*disgord.EmbedField
+	for i := 0; i < len(stats); i += daysPerField {
+		end := i + daysPerField
+		if end > len(stats) {
+			end = len(stats)
+		}
+
+		var lines []string
+		for _, stat := range stats[i:end] {
+			lines = append(lines, fmt.Sprintf(
+				"%s: %d created, %d delivered",
+				time.Unix(stat.Day, 0).UTC().Format("2006-01-02"),
+				stat.Created,
+				stat.Delivered,
+			))
+		}
+		fields = append(fields, &disgord.EmbedField{
+			Name:  "Reminders per day",
+			Value: strings.Join(lines, "\n"),
+		})
+	}
+	return fields
+}
+
+// queueField returns the current state of the command queue.
+func queueField(metrics common.PoolMetrics) *disgord.EmbedField {
+	return &disgord.EmbedField{
+		Name: "Command queue",
+		Value: fmt.Sprintf(
+			"%d/%d queued (at most %d so far) for %d workers, %d processed and %d turned away since the start.",
+			metrics.Depth,
+			metrics.Capacity,
+			metrics.MaxDepth,
+			metrics.Workers,
+			metrics.Processed,
+			metrics.Rejected,
+		),
+	}
+}
+
+// percentage returns part of total formatted as percentage.
+func percentage(part, total int64) string {
+	if total == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(part)*100/float64(total))
+}
+
+func (*Stats) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "period",
+				Description: "Period of the statistics, defaults to week",
+				Optional:    true,
+				Choices:     []string{"today", "week", "month", "all"},
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Showing the statistics of the last 7 days"},
+			{Description: "Showing the statistics of all time", Args: "all"},
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"sort"
+)
+
+// Suggest returns the registered command name or alias most similar to name, of the commands usable returns true for.
+// Only names within an edit distance of one per three characters are suggested, otherwise false is returned.
+func (ci *CommandIndex) Suggest(name string, usable func(Command) bool) (string, bool) {
+	// Iterate in a stable order, so ties are always resolved the same way.
+	var candidates []string
+	for candidate, cmd := range *ci {
+		if usable == nil || usable(cmd) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	sort.Strings(candidates)
+
+	best, bestDistance := "", -1
+	for _, candidate := range candidates {
+		distance := levenshtein(name, candidate)
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	// Names of one or two characters are too short to tell a typo from a different command.
+	maxDistance := len([]rune(name)) / 3
+	if bestDistance < 0 || bestDistance > maxDistance {
+		return "", false
+	}
+	return best, true
+}
+
+// levenshtein returns the edit distance between two strings.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// min returns the smallest of the given integers.
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
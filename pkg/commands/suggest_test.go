@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+func TestSuggest(t *testing.T) {
+	index := &CommandIndex{}
+	index.Register(
+		&testCommand{name: "remind", aliases: []string{"r"}},
+		&testCommand{name: "list", aliases: []string{"ls"}},
+		&testCommand{name: "stats", permission: common.PermissionDeveloper},
+		&testCommand{name: "holidays"},
+	)
+	userCommands := func(cmd Command) bool {
+		return cmd.Permission() <= common.PermissionDefault
+	}
+
+	tests := []struct {
+		name       string
+		suggestion string
+	}{
+		{"remnd", "remind"},
+		{"lsit", ""},
+		{"lst", "list"},
+		{"holidyas", "holidays"},
+		// One or two characters are too short to suggest anything.
+		{"x", ""},
+		{"q", ""},
+		{"rx", ""},
+		// Commands the user may not use are never suggested.
+		{"stat", ""},
+		{"dance", ""},
+	}
+
+	for _, test := range tests {
+		suggestion, ok := index.Suggest(test.name, userCommands)
+		if suggestion != test.suggestion || ok != (test.suggestion != "") {
+			t.Errorf("Suggest(%s) = %q, %v, want %q", test.name, suggestion, ok, test.suggestion)
+		}
+	}
+
+	if suggestion, _ := index.Suggest("stat", nil); suggestion != "stats" {
+		t.Errorf("without a filter every command is suggested, got %q", suggestion)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		distance int
+	}{
+		{"", "", 0},
+		{"remind", "remind", 0},
+		{"remnd", "remind", 1},
+		{"lsit", "list", 2},
+		{"", "abc", 3},
+		{"äbc", "abc", 1},
+	}
+	for _, test := range tests {
+		if got := levenshtein(test.a, test.b); got != test.distance {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", test.a, test.b, got, test.distance)
+		}
+	}
+}
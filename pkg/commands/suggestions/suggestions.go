@@ -0,0 +1,98 @@
+package suggestions
+
+import (
+	"fmt"
+
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+// Suggestions command suggestion setting command.
+type Suggestions struct{}
+
+func Init() *Suggestions {
+	return &Suggestions{}
+}
+
+func (*Suggestions) Name() string {
+	return "suggestions"
+}
+
+func (*Suggestions) Aliases() []string {
+	return []string{"didyoumean"}
+}
+
+func (*Suggestions) Description() string {
+	return "Turn suggestions for mistyped commands on or off in this server."
+}
+
+func (*Suggestions) Category() common.CommandCategory {
+	return common.CategorySettings
+}
+
+func (*Suggestions) Permission() common.PermissionLevel {
+	return common.PermissionDefault
+}
+
+func (*Suggestions) Active() bool {
+	return true
+}
+
+func (*Suggestions) Execute(s common.MessageState) {
+	if s.GuildID().Empty() {
+		s.Reply("Suggestions can only be configured in a server.")
+		return
+	}
+
+	settings, err := common.GetGuildSettings(s.GuildID())
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	if !s.Args.Has("state") {
+		state := "on"
+		if settings.DisableSuggestions {
+			state = "off"
+		}
+		s.Reply(fmt.Sprintf("Suggestions for mistyped commands are %s.", state))
+		return
+	}
+
+	if s.UserPermission() < common.PermissionAdmin {
+		s.Reply("Only server admins can configure suggestions.")
+		return
+	}
+
+	state := s.Args.String("state")
+	settings.DisableSuggestions = state == "off"
+	err = common.SaveGuildSettings(settings)
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	s.Reply(fmt.Sprintf("Suggestions for mistyped commands are now %s.", state))
+}
+
+func (*Suggestions) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "state",
+				Description: "Turn suggestions on or off (server admins only)",
+				Optional:    true,
+				Choices:     []string{"on", "off"},
+			},
+		},
+		Notes: []common.UsageNote{
+			{
+				Name:  "Why turn them off?",
+				Value: "If other bots in this server use the same prefix, their commands would be answered with suggestions.",
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Showing whether suggestions are on"},
+			{Description: "Turning suggestions off", Args: "off"},
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package common
+
+import (
+	"github.com/andersfylling/disgord"
+	"github.com/jinzhu/gorm"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+// FindAlias returns the alias of a user by name, falling back to the aliases of the guild.
+// Empty IDs are skipped. If neither exists, nil is returned.
+func FindAlias(userID, guildID disgord.Snowflake, name string) (*models.Alias, error) {
+	if !userID.Empty() {
+		alias, err := findAlias(models.Alias{UserID: userID, Name: name})
+		if err != nil || alias != nil {
+			return alias, err
+		}
+	}
+	if guildID.Empty() {
+		return nil, nil
+	}
+	return findAlias(models.Alias{GuildID: guildID, Name: name})
+}
+
+// GetAliases returns the personal aliases of a user or, if userID is empty, the aliases of a guild.
+func GetAliases(userID, guildID disgord.Snowflake) ([]models.Alias, error) {
+	var aliases []models.Alias
+	where := models.Alias{UserID: userID}
+	if userID.Empty() {
+		where = models.Alias{GuildID: guildID}
+	}
+	err := DB.Where(where).Order("name").Find(&aliases).Error
+	return aliases, err
+}
+
+// findAlias returns the first alias matching where or nil.
+func findAlias(where models.Alias) (*models.Alias, error) {
+	alias := &models.Alias{}
+	err := DB.Where(where).First(alias).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return alias, nil
+}
@@ -0,0 +1,278 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/andersfylling/disgord"
+)
+
+// ArgumentType represents the type of a command argument.
+type ArgumentType uint
+
+// Command argument type.
+const (
+	// ArgumentString a single word.
+	ArgumentString ArgumentType = iota
+	// ArgumentInteger a single whole number.
+	ArgumentInteger
+	// ArgumentUser a user mention or ID.
+	ArgumentUser
+	// ArgumentChannel a channel mention or ID.
+	ArgumentChannel
+	// ArgumentText all remaining words, which keep their case and spacing.
+	ArgumentText
+	// ArgumentRole a role mention or ID.
+	ArgumentRole
+)
+
+// String returns the human readable name of the argument type.
+func (t ArgumentType) String() string {
+	switch t {
+	case ArgumentInteger:
+		return "Number"
+	case ArgumentUser:
+		return "User"
+	case ArgumentChannel:
+		return "Channel"
+	case ArgumentText:
+		return "Text"
+	case ArgumentRole:
+		return "Role"
+	}
+	return "Word"
+}
+
+// ArgumentParser represents a custom parser of an argument.
+// It receives all remaining arguments and returns the parsed value and the number of consumed arguments.
+type ArgumentParser func(args []string) (value interface{}, consumed int, err error)
+
+// Argument represents the declaration of a command argument.
+type Argument struct {
+	Name        string
+	Description string
+	Type        ArgumentType
+	Optional    bool
+	// Choices restricts a string argument to a set of (lowercase) values.
+	Choices []string
+	// Parse replaces the parsing by Type if set.
+	Parse ArgumentParser
+}
+
+// UsageNote represents additional information shown in the help message of a command.
+type UsageNote struct {
+	Name  string
+	Value string
+}
+
+// UsageExample represents an example invocation of a command.
+type UsageExample struct {
+	Description string
+	Args        string
+}
+
+// CommandUsage represents the declarative usage of a command.
+type CommandUsage struct {
+	Arguments []Argument
+	Notes     []UsageNote
+	Examples  []UsageExample
+}
+
+// Arguments represents parsed command arguments mapped by their name.
+type Arguments map[string]interface{}
+
+// Has returns a bool indicating whether the argument was given.
+func (a Arguments) Has(name string) bool {
+	_, ok := a[name]
+	return ok
+}
+
+// Value returns the parsed value of an argument or nil.
+func (a Arguments) Value(name string) interface{} {
+	return a[name]
+}
+
+// String returns the value of a string or text argument, or an empty string.
+func (a Arguments) String(name string) string {
+	str, _ := a[name].(string)
+	return str
+}
+
+// Int returns the value of an integer argument, or 0.
+func (a Arguments) Int(name string) int64 {
+	i, _ := a[name].(int64)
+	return i
+}
+
+// Snowflake returns the value of a user, channel or role argument, or an empty snowflake.
+func (a Arguments) Snowflake(name string) disgord.Snowflake {
+	id, _ := a[name].(disgord.Snowflake)
+	return id
+}
+
+var (
+	userMentionRegex    = regexp.MustCompile(`^<@!?(\d+)>$`)
+	channelMentionRegex = regexp.MustCompile(`^<#(\d+)>$`)
+	roleMentionRegex    = regexp.MustCompile(`^<@&(\d+)>$`)
+)
+
+// ArgumentError represents an error caused by invalid user input.
+type ArgumentError struct {
+	// Missing is true if no arguments were given although some are required.
+	Missing bool
+	message string
+}
+
+func (e *ArgumentError) Error() string {
+	return e.message
+}
+
+// ParseArguments parses the command arguments according to their declarations.
+// The returned error is always an *ArgumentError.
+func ParseArguments(declared []Argument, args []string) (Arguments, error) {
+	parsed := Arguments{}
+
+	pos := 0
+	for _, arg := range declared {
+		// Ignore superfluous whitespace.
+		for pos < len(args) && args[pos] == "" {
+			pos++
+		}
+
+		if pos >= len(args) {
+			if arg.Optional {
+				continue
+			}
+			return nil, &ArgumentError{
+				Missing: len(parsed) == 0,
+				message: fmt.Sprintf("missing argument \"%s\"", arg.Name),
+			}
+		}
+
+		value, consumed, err := parseArgument(arg, args[pos:])
+		if err != nil {
+			return nil, &ArgumentError{
+				message: fmt.Sprintf("invalid argument \"%s\": %s", arg.Name, err.Error()),
+			}
+		}
+
+		parsed[arg.Name] = value
+		pos += consumed
+	}
+
+	for ; pos < len(args); pos++ {
+		if args[pos] != "" {
+			return nil, &ArgumentError{
+				message: fmt.Sprintf("unexpected argument \"%s\"", args[pos]),
+			}
+		}
+	}
+
+	return parsed, nil
+}
+
+// ParseOptions parses named argument values (e.g. slash command options) according to their declarations.
+// Each value has to be consumed completely by its argument. The returned error is always an *ArgumentError.
+func ParseOptions(declared []Argument, options map[string]string) (Arguments, error) {
+	parsed := Arguments{}
+
+	for _, arg := range declared {
+		value, ok := options[arg.Name]
+		if !ok || strings.TrimSpace(value) == "" {
+			if arg.Optional {
+				continue
+			}
+			return nil, &ArgumentError{
+				Missing: len(options) == 0,
+				message: fmt.Sprintf("missing argument \"%s\"", arg.Name),
+			}
+		}
+
+		words := strings.Fields(value)
+		if arg.Type == ArgumentText && arg.Parse == nil {
+			words = []string{value}
+		}
+
+		v, consumed, err := parseArgument(arg, words)
+		if err == nil && consumed < len(words) {
+			err = fmt.Errorf("unexpected \"%s\"", strings.Join(words[consumed:], " "))
+		}
+		if err != nil {
+			return nil, &ArgumentError{
+				message: fmt.Sprintf("invalid argument \"%s\": %s", arg.Name, err.Error()),
+			}
+		}
+
+		parsed[arg.Name] = v
+	}
+
+	return parsed, nil
+}
+
+// parseArgument parses a single argument from the beginning of args.
+func parseArgument(arg Argument, args []string) (interface{}, int, error) {
+	if arg.Parse != nil {
+		return arg.Parse(args)
+	}
+
+	word := args[0]
+	switch arg.Type {
+	case ArgumentInteger:
+		i, err := strconv.ParseInt(word, 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("\"%s\" is not a number", word)
+		}
+		return i, 1, nil
+
+	case ArgumentUser:
+		id, err := parseMention(userMentionRegex, word)
+		if err != nil {
+			return nil, 0, fmt.Errorf("\"%s\" is not a user", word)
+		}
+		return id, 1, nil
+
+	case ArgumentChannel:
+		id, err := parseMention(channelMentionRegex, word)
+		if err != nil {
+			return nil, 0, fmt.Errorf("\"%s\" is not a channel", word)
+		}
+		return id, 1, nil
+
+	case ArgumentRole:
+		id, err := parseMention(roleMentionRegex, word)
+		if err != nil {
+			return nil, 0, fmt.Errorf("\"%s\" is not a role", word)
+		}
+		return id, 1, nil
+
+	case ArgumentText:
+		return strings.Join(args, " "), len(args), nil
+	}
+
+	if len(arg.Choices) > 0 {
+		word = strings.ToLower(word)
+		for _, choice := range arg.Choices {
+			if word == choice {
+				return word, 1, nil
+			}
+		}
+		return nil, 0, fmt.Errorf("expected one of %s", strings.Join(arg.Choices, ", "))
+	}
+
+	return word, 1, nil
+}
+
+// parseMention parses a Discord mention or a plain ID into a snowflake.
+func parseMention(mention *regexp.Regexp, word string) (disgord.Snowflake, error) {
+	if match := mention.FindStringSubmatch(word); match != nil {
+		word = match[1]
+	}
+
+	id, err := strconv.ParseUint(word, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return disgord.NewSnowflake(id), nil
+}
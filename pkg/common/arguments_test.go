@@ -0,0 +1,98 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/andersfylling/disgord"
+)
+
+var testArguments = []Argument{
+	{Name: "user", Type: ArgumentUser},
+	{Name: "count", Type: ArgumentInteger},
+	{Name: "mode", Choices: []string{"on", "off"}, Optional: true},
+	{Name: "text", Type: ArgumentText, Optional: true},
+}
+
+func TestParseArguments(t *testing.T) {
+	args, err := ParseArguments(testArguments, []string{"<@!123>", "", "42", "ON", "Hello", "", "World"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.Snowflake("user") != disgord.NewSnowflake(123) || args.Int("count") != 42 || args.String("mode") != "on" {
+		t.Errorf("unexpected arguments %v", args)
+	}
+	if args.String("text") != "Hello  World" {
+		t.Errorf("text argument = %q, the spacing must be kept", args.String("text"))
+	}
+
+	args, err = ParseArguments(testArguments, []string{"123", "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.Has("mode") || args.Has("text") {
+		t.Errorf("optional arguments must be missing, got %v", args)
+	}
+}
+
+func TestParseArgumentsErrors(t *testing.T) {
+	tests := []struct {
+		args    []string
+		missing bool
+		message string
+	}{
+		{[]string{}, true, "missing argument \"user\""},
+		{[]string{"<@1>"}, false, "missing argument \"count\""},
+		{[]string{"bob", "1"}, false, "invalid argument \"user\": \"bob\" is not a user"},
+		{[]string{"<@1>", "one"}, false, "invalid argument \"count\": \"one\" is not a number"},
+		{[]string{"<@1>", "1", "maybe"}, false, "invalid argument \"mode\": expected one of on, off"},
+	}
+
+	for _, test := range tests {
+		_, err := ParseArguments(testArguments, test.args)
+		argErr, ok := err.(*ArgumentError)
+		if !ok {
+			t.Errorf("ParseArguments(%q) returned %v, want an *ArgumentError", test.args, err)
+			continue
+		}
+		if argErr.Missing != test.missing || argErr.Error() != test.message {
+			t.Errorf("ParseArguments(%q) = %q (missing %v), want %q (missing %v)", test.args, argErr.Error(), argErr.Missing, test.message, test.missing)
+		}
+	}
+
+	_, err := ParseArguments([]Argument{{Name: "word"}}, []string{"one", "two"})
+	if err == nil || err.Error() != "unexpected argument \"two\"" {
+		t.Errorf("superfluous arguments must be rejected, got %v", err)
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	args, err := ParseOptions(testArguments, map[string]string{
+		"user":  "<@&1>",
+		"count": "3",
+	})
+	if err == nil {
+		t.Errorf("a role is not a user, got %v", args)
+	}
+
+	args, err = ParseOptions(testArguments, map[string]string{
+		"user":  "123",
+		"count": "3",
+		"text":  "keep  this",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.Int("count") != 3 || args.String("text") != "keep  this" {
+		t.Errorf("unexpected arguments %v", args)
+	}
+
+	_, err = ParseOptions(testArguments, map[string]string{"user": "123", "count": "3 4"})
+	if err == nil {
+		t.Error("an option must be consumed completely")
+	}
+
+	_, err = ParseOptions(testArguments, map[string]string{})
+	if argErr, ok := err.(*ArgumentError); !ok || !argErr.Missing {
+		t.Errorf("no options must be reported as missing, got %v", err)
+	}
+}
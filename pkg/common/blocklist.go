@@ -0,0 +1,86 @@
+package common
+
+import (
+	"sync"
+
+	"github.com/andersfylling/disgord"
+	"github.com/jinzhu/gorm"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+var (
+	// blockedUsers caches whether users are blocked since it is needed for every message.
+	blockedUsers   = map[disgord.Snowflake]bool{}
+	blockedUsersMu sync.RWMutex
+)
+
+// IsBlocked returns a bool which indicates whether the bot ignores the user.
+func IsBlocked(userID disgord.Snowflake) bool {
+	blockedUsersMu.RLock()
+	blocked, ok := blockedUsers[userID]
+	blockedUsersMu.RUnlock()
+	if ok {
+		return blocked
+	}
+
+	err := DB.Where(models.BlockedUser{
+		UserID: userID,
+	}).First(&models.BlockedUser{}).Error
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		// Errors are not cached, the next message tries again.
+		Logger.Error(err)
+		return false
+	}
+	blocked = err == nil
+
+	blockedUsersMu.Lock()
+	blockedUsers[userID] = blocked
+	blockedUsersMu.Unlock()
+
+	return blocked
+}
+
+// GetBlockedUsers returns all blocked users in order of blocking.
+func GetBlockedUsers() ([]models.BlockedUser, error) {
+	var users []models.BlockedUser
+	err := DB.Order("id").Find(&users).Error
+	return users, err
+}
+
+// BlockUser makes the bot ignore a user, blocking an already blocked user updates the reason.
+func BlockUser(userID, blockedBy disgord.Snowflake, reason string) error {
+	user := &models.BlockedUser{}
+	err := DB.Where(models.BlockedUser{
+		UserID: userID,
+	}).FirstOrInit(user).Error
+	if err != nil {
+		return err
+	}
+	user.Reason = reason
+	user.BlockedBy = blockedBy
+
+	err = DB.Save(user).Error
+	if err == nil {
+		setBlocked(userID, true)
+	}
+	return err
+}
+
+// UnblockUser stops ignoring a user and returns whether they were blocked.
+func UnblockUser(userID disgord.Snowflake) (bool, error) {
+	db := DB.Unscoped().Where(models.BlockedUser{
+		UserID: userID,
+	}).Delete(&models.BlockedUser{})
+	if db.Error != nil {
+		return false, db.Error
+	}
+	setBlocked(userID, false)
+	return db.RowsAffected > 0, nil
+}
+
+// setBlocked updates the cache of a user.
+func setBlocked(userID disgord.Snowflake, blocked bool) {
+	blockedUsersMu.Lock()
+	blockedUsers[userID] = blocked
+	blockedUsersMu.Unlock()
+}
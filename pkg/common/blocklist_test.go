@@ -0,0 +1,95 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andersfylling/disgord"
+)
+
+func TestBlocklist(t *testing.T) {
+	const (
+		user  = disgord.Snowflake(50)
+		other = disgord.Snowflake(51)
+		owner = disgord.Snowflake(1)
+	)
+	defer UnblockUser(user)
+	defer UnblockUser(other)
+
+	if IsBlocked(user) {
+		t.Fatal("nobody is blocked yet")
+	}
+
+	if err := BlockUser(user, owner, "spam"); err != nil {
+		t.Fatal(err)
+	}
+	if err := BlockUser(other, owner, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !IsBlocked(user) || !IsBlocked(other) {
+		t.Error("blocked users must be ignored right away")
+	}
+
+	// Blocking again updates the reason instead of adding the user twice.
+	if err := BlockUser(user, owner, "reminder spam"); err != nil {
+		t.Fatal(err)
+	}
+	users, err := GetBlockedUsers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0].UserID != user || users[0].Reason != "reminder spam" || users[1].UserID != other {
+		t.Errorf("got %+v", users)
+	}
+
+	unblocked, err := UnblockUser(user)
+	if err != nil || !unblocked {
+		t.Errorf("got %v, %v", unblocked, err)
+	}
+	if IsBlocked(user) {
+		t.Error("unblocked users must be handled again right away")
+	}
+	if unblocked, _ := UnblockUser(user); unblocked {
+		t.Error("unblocking a user twice must report that they were not blocked")
+	}
+
+	// The cache is filled from the database.
+	setBlocked(other, false)
+	blockedUsersMu.Lock()
+	delete(blockedUsers, other)
+	blockedUsersMu.Unlock()
+	if !IsBlocked(other) {
+		t.Error("blocked users must be read from the database")
+	}
+}
+
+func TestIsOwner(t *testing.T) {
+	config := &Config{OwnerIDs: []disgord.Snowflake{1, 2}}
+	if !config.IsOwner(1) || !config.IsOwner(2) || config.IsOwner(3) {
+		t.Error("only the configured users are owners")
+	}
+
+	var none *Config
+	if none.IsOwner(1) {
+		t.Error("nobody is an owner without config")
+	}
+}
+
+func TestParseSnowflakes(t *testing.T) {
+	ids, err := ParseSnowflakes(" 1, 2,,3 ")
+	if err != nil || !reflect.DeepEqual(ids, []disgord.Snowflake{1, 2, 3}) {
+		t.Errorf("got %v, %v", ids, err)
+	}
+
+	ids, err = ParseSnowflakes("1,abc,0,-4,2")
+	if err == nil || err.Error() != "invalid IDs: abc, 0, -4" {
+		t.Errorf("got error %v", err)
+	}
+	if !reflect.DeepEqual(ids, []disgord.Snowflake{1, 2}) {
+		t.Errorf("valid IDs must be kept, got %v", ids)
+	}
+
+	if ids, err := ParseSnowflakes(""); err != nil || len(ids) != 0 {
+		t.Errorf("got %v, %v for an empty list", ids, err)
+	}
+}
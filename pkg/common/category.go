@@ -0,0 +1,20 @@
+package common
+
+// CommandCategory represents the category a command is listed under in the help message.
+type CommandCategory string
+
+// Command category, in order of appearance in the help message.
+const (
+	CategoryGeneral   CommandCategory = "General"
+	CategoryReminders CommandCategory = "Reminders"
+	CategorySettings  CommandCategory = "Settings"
+	CategoryPlugins   CommandCategory = "Plugins"
+)
+
+// CommandCategories represents all command categories in order of appearance.
+var CommandCategories = []CommandCategory{
+	CategoryGeneral,
+	CategoryReminders,
+	CategorySettings,
+	CategoryPlugins,
+}
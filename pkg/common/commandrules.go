@@ -0,0 +1,168 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+// GetCommandRules returns all command rules of a guild in order of creation.
+func GetCommandRules(guildID disgord.Snowflake) ([]models.CommandRule, error) {
+	var rules []models.CommandRule
+	err := DB.Where(models.CommandRule{
+		GuildID: guildID,
+	}).Order("id").Find(&rules).Error
+	return rules, err
+}
+
+// SetCommandRule saves a command rule, it replaces the rule of the same command and target.
+func SetCommandRule(rule *models.CommandRule) error {
+	existing := &models.CommandRule{}
+	err := DB.Where(models.CommandRule{
+		GuildID:    rule.GuildID,
+		Command:    rule.Command,
+		TargetType: rule.TargetType,
+		TargetID:   rule.TargetID,
+	}).FirstOrInit(existing).Error
+	if err != nil {
+		return err
+	}
+	rule.Model = existing.Model
+	return DB.Save(rule).Error
+}
+
+// DeleteCommandRule deletes a command rule of a guild by ID and returns whether it existed.
+func DeleteCommandRule(guildID disgord.Snowflake, id uint) (bool, error) {
+	db := DB.Unscoped().Where("id = ? AND guild_id = ?", id, guildID).Delete(&models.CommandRule{})
+	return db.RowsAffected > 0, db.Error
+}
+
+// ParseRuleTarget parses a user, role or channel mention into a command rule without command and effect.
+func ParseRuleTarget(args []string) (interface{}, int, error) {
+	targets := []struct {
+		kind    string
+		mention *regexp.Regexp
+	}{
+		{models.RuleTargetRole, roleMentionRegex},
+		{models.RuleTargetUser, userMentionRegex},
+		{models.RuleTargetChannel, channelMentionRegex},
+	}
+
+	for _, target := range targets {
+		if !target.mention.MatchString(args[0]) {
+			continue
+		}
+		id, err := parseMention(target.mention, args[0])
+		if err != nil {
+			break
+		}
+		return &models.CommandRule{TargetType: target.kind, TargetID: id}, 1, nil
+	}
+	return nil, 0, fmt.Errorf("\"%s\" is not a mention of a user, role or channel", args[0])
+}
+
+// RuleSubject represents who uses a command where, the roles are only needed if role rules exist.
+type RuleSubject struct {
+	UserID    disgord.Snowflake
+	ChannelID disgord.Snowflake
+	Roles     []disgord.Snowflake
+}
+
+// RuleDecision represents the outcome of evaluating command rules.
+type RuleDecision struct {
+	Allowed bool
+	// Rule is the deciding rule, nil if no rule matched.
+	Rule *models.CommandRule
+	// Command is the command whose rules decided, empty if no rules applied.
+	Command string
+}
+
+// HasRoleRules returns a bool which indicates whether any of the rules of the commands targets a role.
+func HasRoleRules(rules []models.CommandRule, commands []string) bool {
+	for _, rule := range rules {
+		if rule.TargetType == models.RuleTargetRole && containsString(commands, rule.Command) {
+			return true
+		}
+	}
+	return false
+}
+
+// EvaluateCommandRules decides whether the subject may use a command.
+// Commands are ordered from most to least specific, e.g. ["reminder add", "remind", "reminder"].
+//
+// Users and roles (member rules) and channels (channel rules) are evaluated separately and both must allow.
+// For each of them the most specific command with a matching rule decides; user rules precede role rules,
+// and deny precedes allow. A command with allow rules of which none matches denies everyone else.
+// Without any applying rule the command is allowed.
+func EvaluateCommandRules(rules []models.CommandRule, commands []string, subject RuleSubject) RuleDecision {
+	roles := map[disgord.Snowflake]bool{}
+	for _, id := range subject.Roles {
+		roles[id] = true
+	}
+
+	member := evaluateRules(rules, commands, []string{models.RuleTargetUser, models.RuleTargetRole}, func(rule *models.CommandRule) bool {
+		if rule.TargetType == models.RuleTargetUser {
+			return rule.TargetID == subject.UserID
+		}
+		return roles[rule.TargetID]
+	})
+	if !member.Allowed {
+		return member
+	}
+
+	channel := evaluateRules(rules, commands, []string{models.RuleTargetChannel}, func(rule *models.CommandRule) bool {
+		return rule.TargetID == subject.ChannelID
+	})
+	if !channel.Allowed || member.Rule == nil {
+		return channel
+	}
+	return member
+}
+
+// evaluateRules evaluates the rules of the target types, which are ordered by precedence.
+func evaluateRules(rules []models.CommandRule, commands []string, types []string, matches func(*models.CommandRule) bool) RuleDecision {
+	for _, command := range commands {
+		var allowList bool
+		for _, targetType := range types {
+			var allow *models.CommandRule
+			for i := range rules {
+				rule := &rules[i]
+				if rule.Command != command || rule.TargetType != targetType {
+					continue
+				}
+				if rule.Allow {
+					allowList = true
+				}
+				if !matches(rule) {
+					continue
+				}
+				if !rule.Allow {
+					return RuleDecision{Allowed: false, Rule: rule, Command: command}
+				}
+				if allow == nil {
+					allow = rule
+				}
+			}
+			if allow != nil {
+				return RuleDecision{Allowed: true, Rule: allow, Command: command}
+			}
+		}
+
+		if allowList {
+			return RuleDecision{Allowed: false, Command: command}
+		}
+	}
+	return RuleDecision{Allowed: true}
+}
+
+// containsString returns a bool which indicates whether the slice contains str.
+func containsString(slice []string, str string) bool {
+	for _, s := range slice {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+// rule returns a command rule with an ID for identifying the deciding rule.
+func rule(id uint, command, targetType string, targetID disgord.Snowflake, allow bool) models.CommandRule {
+	r := models.CommandRule{Command: command, TargetType: targetType, TargetID: targetID, Allow: allow}
+	r.ID = id
+	return r
+}
+
+func TestEvaluateCommandRules(t *testing.T) {
+	const (
+		user    = disgord.Snowflake(2)
+		other   = disgord.Snowflake(3)
+		role    = disgord.Snowflake(10)
+		channel = disgord.Snowflake(20)
+	)
+	subject := RuleSubject{UserID: user, ChannelID: channel, Roles: []disgord.Snowflake{role}}
+	names := []string{"reminder add", "remind", "reminder"}
+
+	tests := []struct {
+		name    string
+		rules   []models.CommandRule
+		allowed bool
+		// decidedBy is the ID of the deciding rule, 0 if none.
+		decidedBy uint
+	}{
+		{"no rules", nil, true, 0},
+		{"other commands", []models.CommandRule{rule(1, "stats", models.RuleTargetUser, user, false)}, true, 0},
+		{"user deny", []models.CommandRule{rule(1, "remind", models.RuleTargetUser, user, false)}, false, 1},
+		{"role deny", []models.CommandRule{rule(1, "reminder", models.RuleTargetRole, role, false)}, false, 1},
+		{"channel deny", []models.CommandRule{rule(1, "reminder add", models.RuleTargetChannel, channel, false)}, false, 1},
+		{"allow list of others", []models.CommandRule{rule(1, "remind", models.RuleTargetUser, other, true)}, false, 0},
+		{"allow list including the user", []models.CommandRule{
+			rule(1, "remind", models.RuleTargetUser, other, true),
+			rule(2, "remind", models.RuleTargetUser, user, true),
+		}, true, 2},
+		{"user precedes role", []models.CommandRule{
+			rule(1, "remind", models.RuleTargetRole, role, false),
+			rule(2, "remind", models.RuleTargetUser, user, true),
+		}, true, 2},
+		{"deny precedes allow", []models.CommandRule{
+			rule(1, "remind", models.RuleTargetRole, role, true),
+			rule(2, "remind", models.RuleTargetRole, role, false),
+		}, false, 2},
+		{"subcommand precedes group", []models.CommandRule{
+			rule(1, "reminder", models.RuleTargetUser, user, false),
+			rule(2, "reminder add", models.RuleTargetRole, role, true),
+		}, true, 2},
+		{"group applies to subcommands", []models.CommandRule{
+			rule(1, "reminder list", models.RuleTargetUser, user, true),
+			rule(2, "reminder", models.RuleTargetUser, user, false),
+		}, false, 2},
+		{"member and channel must both allow", []models.CommandRule{
+			rule(1, "remind", models.RuleTargetUser, user, true),
+			rule(2, "reminder", models.RuleTargetChannel, channel, false),
+		}, false, 2},
+		{"channel allow with member allow", []models.CommandRule{
+			rule(1, "remind", models.RuleTargetUser, user, true),
+			rule(2, "remind", models.RuleTargetChannel, channel, true),
+		}, true, 1},
+		{"channel allow list of other channels", []models.CommandRule{
+			rule(1, "remind", models.RuleTargetChannel, channel+1, true),
+		}, false, 0},
+		{"channel allow without member rules", []models.CommandRule{
+			rule(1, "remind", models.RuleTargetChannel, channel, true),
+		}, true, 1},
+	}
+
+	for _, test := range tests {
+		decision := EvaluateCommandRules(test.rules, names, subject)
+		if decision.Allowed != test.allowed {
+			t.Errorf("%s: got allowed %v, want %v", test.name, decision.Allowed, test.allowed)
+		}
+		var decidedBy uint
+		if decision.Rule != nil {
+			decidedBy = decision.Rule.ID
+		}
+		if decidedBy != test.decidedBy {
+			t.Errorf("%s: decided by rule %d, want %d", test.name, decidedBy, test.decidedBy)
+		}
+	}
+}
+
+func TestHasRoleRules(t *testing.T) {
+	rules := []models.CommandRule{
+		rule(1, "remind", models.RuleTargetUser, 2, false),
+		rule(2, "stats", models.RuleTargetRole, 10, false),
+	}
+	if HasRoleRules(rules, []string{"remind"}) {
+		t.Error("remind has no role rules")
+	}
+	if !HasRoleRules(rules, []string{"remind", "stats"}) {
+		t.Error("stats has a role rule")
+	}
+}
+
+func TestParseRuleTarget(t *testing.T) {
+	tests := []struct {
+		arg        string
+		targetType string
+		targetID   disgord.Snowflake
+	}{
+		{"<@2>", models.RuleTargetUser, 2},
+		{"<@!2>", models.RuleTargetUser, 2},
+		{"<@&10>", models.RuleTargetRole, 10},
+		{"<#20>", models.RuleTargetChannel, 20},
+		{"@everyone", "", 0},
+		{"2", "", 0},
+		{"#general", "", 0},
+	}
+
+	for _, test := range tests {
+		value, n, err := ParseRuleTarget([]string{test.arg, "rest"})
+		if test.targetType == "" {
+			if err == nil {
+				t.Errorf("ParseRuleTarget(%q) must fail", test.arg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRuleTarget(%q) failed: %s", test.arg, err)
+			continue
+		}
+		r := value.(*models.CommandRule)
+		if n != 1 || r.TargetType != test.targetType || r.TargetID != test.targetID {
+			t.Errorf("ParseRuleTarget(%q) = %s %d (%d words)", test.arg, r.TargetType, r.TargetID, n)
+		}
+	}
+}
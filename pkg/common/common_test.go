@@ -0,0 +1,23 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	InitNopLogger()
+
+	dir, err := ioutil.TempDir("", "disgotify")
+	if err != nil {
+		panic(err)
+	}
+	InitDB(dir)
+
+	code := m.Run()
+
+	DB.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
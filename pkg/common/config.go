@@ -1,56 +1,500 @@
 package common
 
 import (
-	"log"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/andersfylling/disgord"
+	"github.com/joho/godotenv"
+	"golang.org/x/crypto/ed25519"
+	"gopkg.in/yaml.v2"
 )
 
-// Environmental variable.
-var (
-	DatabaseDir      string
-	DiscordToken     string
-	DeveloperID      disgord.Snowflake
-	CommandPrefix    string
-	ReminderInterval time.Duration
-	Debug						 bool
-)
+// Config represents the configuration of the bot.
+type Config struct {
+	// Path is the config file the config was loaded from, empty if none.
+	Path string `setting:"-"`
+	// DatabaseDir is the directory the database is saved in.
+	DatabaseDir  string `setting:"DATABASE_DIR,restart"`
+	DiscordToken string `setting:"DISCORD_TOKEN,restart"`
+	// OwnerIDs are the users with the developer permission level.
+	OwnerIDs      []disgord.Snowflake `setting:"OWNER_IDS"`
+	CommandPrefix string              `setting:"COMMAND_PREFIX"`
+	// ReminderInterval is the interval in which due reminders are sent.
+	ReminderInterval time.Duration `setting:"REMINDER_INTERVAL"`
+	Debug            bool          `setting:"DEBUG"`
+	// HolidayDir is the directory containing iCalendar (.ics) files with holidays.
+	HolidayDir string `setting:"HOLIDAY_DIR,restart"`
+	// DefaultHolidayCalendar is used if a guild did not select one (e.g. in DMs).
+	DefaultHolidayCalendar string `setting:"HOLIDAY_CALENDAR"`
+	// Maintenance lets only owners use commands.
+	Maintenance bool `setting:"MAINTENANCE"`
+	// Cooldowns replace the ones declared by the commands.
+	Cooldowns map[string][]Cooldown `setting:"COOLDOWNS"`
+	// InteractionsAddr is the address of the HTTP server receiving slash commands, disabled if empty.
+	InteractionsAddr string `setting:"INTERACTIONS_ADDR,restart"`
+	// DiscordPublicKey is the hex encoded public key of the Discord application, used to verify interactions.
+	DiscordPublicKey string `setting:"DISCORD_PUBLIC_KEY,restart"`
+	// PluginDir is the directory containing executables which are registered as commands.
+	PluginDir string `setting:"PLUGIN_DIR,restart"`
+	// PluginTimeout is the time a plugin has to reply to an invocation.
+	PluginTimeout time.Duration `setting:"PLUGIN_TIMEOUT"`
+	// CommandTimeout is the time a command has to finish before it is cancelled.
+	CommandTimeout time.Duration `setting:"COMMAND_TIMEOUT"`
+	// CommandTimeouts replace the ones declared by the commands.
+	CommandTimeouts map[string]time.Duration `setting:"COMMAND_TIMEOUTS"`
+	// Workers is the number of workers handling commands.
+	Workers int `setting:"WORKERS,restart"`
+	// QueueSize is the number of commands queued per worker before new ones are turned away.
+	QueueSize int `setting:"QUEUE_SIZE,restart"`
+	// GuildAllowlist are the guilds the bot may be used in, all guilds if empty.
+	GuildAllowlist []disgord.Snowflake `setting:"GUILD_ALLOWLIST"`
+	// DormantGuilds makes the bot stay in unlisted guilds without responding instead of leaving them.
+	DormantGuilds bool `setting:"GUILD_ALLOWLIST_MODE"`
+}
+
+// configKeys are the names of all settings, as environment variables and (case insensitive) in the config file.
+var configKeys = []string{
+	"DATABASE_DIR",
+	"DISCORD_TOKEN",
+	"DEVELOPER_ID",
+	"OWNER_IDS",
+	"COMMAND_PREFIX",
+	"REMINDER_INTERVAL",
+	"DEBUG",
+	"HOLIDAY_DIR",
+	"HOLIDAY_CALENDAR",
+	"MAINTENANCE",
+	"COOLDOWNS",
+	"INTERACTIONS_ADDR",
+	"DISCORD_PUBLIC_KEY",
+	"PLUGIN_DIR",
+	"PLUGIN_TIMEOUT",
+	"COMMAND_TIMEOUT",
+	"COMMAND_TIMEOUTS",
+	"WORKERS",
+	"QUEUE_SIZE",
+	"GUILD_ALLOWLIST",
+	"GUILD_ALLOWLIST_MODE",
+}
+
+// ConfigError represents all problems found in a configuration.
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// LoadConfig reads the settings from the optional JSON, YAML or TOML config file, overrides them with
+// the optional .env file and the environment, and validates them. The returned error is a *ConfigError listing every problem;
+// the config is returned anyway with defaults in place of invalid settings.
+func LoadConfig(path string) (*Config, error) {
+	values := map[string]string{}
+	var problems []string
+
+	if path != "" {
+		file, err := readConfigFile(path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("config file %s: %s", path, err.Error()))
+		}
+		if file != nil {
+			values = file
+		}
+	}
+
+	// The .env file is read on every load instead of being loaded into the environment, so reloads see its changes.
+	dotenv, err := godotenv.Read(".env")
+	if err != nil && !os.IsNotExist(err) {
+		problems = append(problems, fmt.Sprintf(".env file: %s", err.Error()))
+	}
+	for _, key := range configKeys {
+		if value := dotenv[key]; value != "" {
+			values[key] = value
+		}
+		if value, ok := os.LookupEnv(key); ok && value != "" {
+			values[key] = value
+		}
+	}
 
-// LoadEnv loads the environment file and panics if it does not exist.
-func LoadEnv() {
-	if _, err := os.Stat(".env"); os.IsNotExist(err) {
-		log.Fatal(".env file is missing")
+	p := &configParser{values: values, problems: problems}
+	config := p.parse()
+	config.Path = path
+	if len(p.problems) > 0 {
+		return config, &ConfigError{Problems: p.problems}
+	}
+	return config, nil
+}
+
+// ConfigChanges represents the settings which differ between two configs.
+type ConfigChanges struct {
+	// Applied are the settings which take effect while running.
+	Applied []string
+	// Restart are the settings which only take effect after a restart.
+	Restart []string
+}
+
+// Reload returns next with the settings which require a restart kept from c, and the changed settings.
+func (c *Config) Reload(next *Config) (*Config, ConfigChanges) {
+	reloaded := *next
+	var changes ConfigChanges
+
+	current := reflect.ValueOf(c).Elem()
+	merged := reflect.ValueOf(&reloaded).Elem()
+	for i := 0; i < current.NumField(); i++ {
+		setting := strings.Split(current.Type().Field(i).Tag.Get("setting"), ",")
+		if setting[0] == "-" || reflect.DeepEqual(current.Field(i).Interface(), merged.Field(i).Interface()) {
+			continue
+		}
+
+		if len(setting) > 1 && setting[1] == "restart" {
+			merged.Field(i).Set(current.Field(i))
+			changes.Restart = append(changes.Restart, setting[0])
+		} else {
+			changes.Applied = append(changes.Applied, setting[0])
+		}
 	}
 
-	// Directory where the database will be saved.
-	DatabaseDir = os.Getenv("DATABASE_DIR")
+	return &reloaded, changes
+}
 
-	// Discord bot token.
-	DiscordToken = os.Getenv("DISCORD_TOKEN")
+// IsOwner returns a bool which indicates whether the user is one of the bot's owners.
+func (c *Config) IsOwner(userID disgord.Snowflake) bool {
+	if c == nil {
+		return false
+	}
+	for _, id := range c.OwnerIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
 
-	// Developer (Discord user) ID
-	id, err := strconv.ParseUint(os.Getenv("DEVELOPER_ID"), 10, 64)
+// IsGuildAllowed returns a bool which indicates whether the bot may be used in the guild.
+// Direct messages are always allowed.
+func (c *Config) IsGuildAllowed(guildID disgord.Snowflake) bool {
+	if c == nil || len(c.GuildAllowlist) == 0 || guildID.Empty() {
+		return true
+	}
+	for _, id := range c.GuildAllowlist {
+		if id == guildID {
+			return true
+		}
+	}
+	return false
+}
+
+// configParser converts the raw settings and collects their problems.
+type configParser struct {
+	values   map[string]string
+	problems []string
+}
+
+// parse returns the config of the raw settings.
+func (p *configParser) parse() *Config {
+	config := &Config{
+		DatabaseDir:            p.values["DATABASE_DIR"],
+		DiscordToken:           p.values["DISCORD_TOKEN"],
+		CommandPrefix:          p.values["COMMAND_PREFIX"],
+		ReminderInterval:       p.interval("REMINDER_INTERVAL", 10*time.Second),
+		Debug:                  p.boolean("DEBUG"),
+		HolidayDir:             p.values["HOLIDAY_DIR"],
+		DefaultHolidayCalendar: strings.ToLower(p.values["HOLIDAY_CALENDAR"]),
+		Maintenance:            p.boolean("MAINTENANCE"),
+		InteractionsAddr:       p.values["INTERACTIONS_ADDR"],
+		DiscordPublicKey:       p.values["DISCORD_PUBLIC_KEY"],
+		PluginDir:              p.values["PLUGIN_DIR"],
+		PluginTimeout:          p.duration("PLUGIN_TIMEOUT", 10*time.Second),
+		CommandTimeout:         p.duration("COMMAND_TIMEOUT", 30*time.Second),
+		Workers:                p.integer("WORKERS", 8),
+		QueueSize:              p.integer("QUEUE_SIZE", 25),
+		GuildAllowlist:         p.snowflakes("GUILD_ALLOWLIST"),
+	}
+
+	// `DEVELOPER_ID` is the first owner.
+	config.OwnerIDs = append(p.snowflakes("DEVELOPER_ID"), p.snowflakes("OWNER_IDS")...)
+
+	if config.DiscordToken == "" {
+		p.problem("DISCORD_TOKEN is missing")
+	}
+	if config.CommandPrefix == "" {
+		p.problem("COMMAND_PREFIX is missing")
+	}
+
+	p.writableDir("DATABASE_DIR", config.DatabaseDir)
+	p.readableDir("HOLIDAY_DIR", config.HolidayDir)
+	p.readableDir("PLUGIN_DIR", config.PluginDir)
+
+	cooldowns, err := ParseCooldowns(p.values["COOLDOWNS"])
 	if err != nil {
-		id = 0
+		p.problem(fmt.Sprintf("COOLDOWNS: %s", err.Error()))
+		cooldowns = map[string][]Cooldown{}
 	}
-	DeveloperID = disgord.NewSnowflake(id)
+	config.Cooldowns = cooldowns
 
-	// Global command prefix.
-	CommandPrefix = os.Getenv("COMMAND_PREFIX")
+	timeouts, err := ParseTimeouts(p.values["COMMAND_TIMEOUTS"])
+	if err != nil {
+		p.problem(fmt.Sprintf("COMMAND_TIMEOUTS: %s", err.Error()))
+		timeouts = map[string]time.Duration{}
+	}
+	config.CommandTimeouts = timeouts
+
+	if config.InteractionsAddr != "" {
+		key, err := hex.DecodeString(config.DiscordPublicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			p.problem("DISCORD_PUBLIC_KEY is missing or not a hex encoded Ed25519 public key, but INTERACTIONS_ADDR is set")
+		}
+	}
 
-	// Reminder interval.
-	interval, err := strconv.ParseInt(os.Getenv("REMINDER_INTERVAL"), 10, 64)
+	switch mode := strings.ToLower(p.values["GUILD_ALLOWLIST_MODE"]); mode {
+	case "", "leave":
+	case "dormant":
+		config.DormantGuilds = true
+	default:
+		p.problem(fmt.Sprintf("GUILD_ALLOWLIST_MODE \"%s\" is neither leave nor dormant", mode))
+	}
+
+	return config
+}
+
+// problem records a problem of the settings.
+func (p *configParser) problem(problem string) {
+	p.problems = append(p.problems, problem)
+}
+
+// boolean parses a setting which is false by default.
+func (p *configParser) boolean(key string) bool {
+	value := p.values[key]
+	if value == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
 	if err != nil {
-		interval = 10000
+		p.problem(fmt.Sprintf("%s \"%s\" is not a boolean", key, value))
 	}
-	ReminderInterval = time.Duration(interval) * time.Millisecond
+	return b
+}
+
+// integer parses a positive whole number setting.
+func (p *configParser) integer(key string, def int) int {
+	value := p.values[key]
+	if value == "" {
+		return def
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 1 {
+		p.problem(fmt.Sprintf("%s \"%s\" is not a positive number", key, value))
+		return def
+	}
+	return i
+}
 
-	debug, err := strconv.ParseBool(os.Getenv("DEBUG"))
+// duration parses a positive duration setting, e.g. "10s".
+func (p *configParser) duration(key string, def time.Duration) time.Duration {
+	value := p.values[key]
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		p.problem(fmt.Sprintf("%s \"%s\" is not a positive duration, e.g. 10s", key, value))
+		return def
+	}
+	return d
+}
+
+// interval parses a positive duration setting, which may be given in milliseconds as well.
+func (p *configParser) interval(key string, def time.Duration) time.Duration {
+	if ms, err := strconv.ParseInt(p.values[key], 10, 64); err == nil {
+		if ms <= 0 {
+			p.problem(fmt.Sprintf("%s \"%d\" is not a positive number of milliseconds", key, ms))
+			return def
+		}
+		return time.Duration(ms) * time.Millisecond
+	}
+	return p.duration(key, def)
+}
+
+// snowflakes parses a comma separated list of IDs.
+func (p *configParser) snowflakes(key string) []disgord.Snowflake {
+	ids, err := ParseSnowflakes(p.values[key])
+	if err != nil {
+		p.problem(fmt.Sprintf("%s: %s", key, err.Error()))
+	}
+	return ids
+}
+
+// readableDir checks that a configured directory exists.
+func (p *configParser) readableDir(key, dir string) {
+	if dir == "" {
+		return
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		p.problem(fmt.Sprintf("%s: %s", key, err.Error()))
+		return
+	}
+	if !info.IsDir() {
+		p.problem(fmt.Sprintf("%s: %s is not a directory", key, dir))
+	}
+}
+
+// writableDir checks that files can be created in a directory, the working directory if empty.
+func (p *configParser) writableDir(key, dir string) {
+	if dir == "" {
+		dir = "."
+	}
+	file, err := ioutil.TempFile(dir, ".disgotify-")
+	if err != nil {
+		p.problem(fmt.Sprintf("%s: %s is not writable: %s", key, dir, err.Error()))
+		return
+	}
+	file.Close()
+	os.Remove(file.Name())
+}
+
+// readConfigFile reads the settings of a JSON, YAML or TOML config file, which are strings, numbers, booleans and lists.
+// Unknown settings are reported, but the known ones are returned anyway.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	decode, ok := configDecoders[ext]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format \"%s\", use .json, .yaml or .toml", ext)
+	}
+	raw, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	values, err := configValues(raw)
 	if err != nil {
-		debug = false
+		return nil, err
+	}
+
+	var unknown []string
+	for key := range values {
+		if !containsString(configKeys, key) {
+			unknown = append(unknown, strings.ToLower(key))
+			delete(values, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return values, fmt.Errorf("unknown settings %s", strings.Join(unknown, ", "))
+	}
+	return values, nil
+}
+
+// configDecoders decode the config file formats by extension.
+var configDecoders = map[string]func(data []byte) (map[string]interface{}, error){
+	".json": decodeJSONConfig,
+	".yaml": decodeYAMLConfig,
+	".yml":  decodeYAMLConfig,
+	".toml": decodeTOMLConfig,
+}
+
+// configKey normalizes a key of the config file, e.g. "database-dir" to "DATABASE_DIR".
+func configKey(key string) string {
+	return strings.ToUpper(strings.Replace(strings.TrimSpace(key), "-", "_", -1))
+}
+
+// listSeparator returns the separator of the list entries of a setting.
+func listSeparator(key string) string {
+	if key == "COOLDOWNS" || key == "COMMAND_TIMEOUTS" {
+		return ";"
+	}
+	return ","
+}
+
+// decodeJSONConfig decodes a JSON object of settings.
+func decodeJSONConfig(data []byte) (map[string]interface{}, error) {
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// IDs don't fit into float64.
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// decodeYAMLConfig decodes a YAML mapping of settings.
+func decodeYAMLConfig(data []byte) (map[string]interface{}, error) {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// decodeTOMLConfig decodes the top-level keys of a TOML document as settings.
+func decodeTOMLConfig(data []byte) (map[string]interface{}, error) {
+	var raw map[string]interface{}
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// configValues converts decoded settings into strings as they would be set in the environment,
+// lists are joined with the setting's separator.
+func configValues(raw map[string]interface{}) (map[string]string, error) {
+	values := map[string]string{}
+	for key, value := range raw {
+		key = configKey(key)
+		switch v := value.(type) {
+		case nil:
+		case []interface{}:
+			var entries []string
+			for _, entry := range v {
+				entries = append(entries, fmt.Sprint(entry))
+			}
+			values[key] = strings.Join(entries, listSeparator(key))
+		case map[string]interface{}, map[interface{}]interface{}, []map[string]interface{}:
+			return nil, fmt.Errorf("%s cannot be an object", strings.ToLower(key))
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+	return values, nil
+}
+
+// ParseSnowflakes parses a comma separated list of IDs, invalid IDs are skipped and reported.
+func ParseSnowflakes(value string) ([]disgord.Snowflake, error) {
+	var ids []disgord.Snowflake
+	var invalid []string
+	for _, word := range strings.Split(value, ",") {
+		word = strings.TrimSpace(word)
+		if word == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(word, 10, 64)
+		if err != nil || id == 0 {
+			invalid = append(invalid, word)
+			continue
+		}
+		ids = append(ids, disgord.NewSnowflake(id))
+	}
+
+	if len(invalid) > 0 {
+		return ids, fmt.Errorf("invalid IDs: %s", strings.Join(invalid, ", "))
 	}
-	Debug = debug
+	return ids, nil
 }
@@ -0,0 +1,304 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andersfylling/disgord"
+)
+
+// setEnv replaces all settings in the environment with env and returns a function restoring them.
+func setEnv(env map[string]string) func() {
+	saved := map[string]string{}
+	for _, key := range configKeys {
+		if value, ok := os.LookupEnv(key); ok {
+			saved[key] = value
+		}
+		os.Unsetenv(key)
+	}
+	for key, value := range env {
+		os.Setenv(key, value)
+	}
+
+	return func() {
+		for _, key := range configKeys {
+			os.Unsetenv(key)
+		}
+		for key, value := range saved {
+			os.Setenv(key, value)
+		}
+	}
+}
+
+// writeConfig writes a config file into a temporary directory and returns its path.
+func writeConfig(t *testing.T, name, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "disgotify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	defer setEnv(nil)()
+
+	dir, err := ioutil.TempDir("", "disgotify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path, cleanup := writeConfig(t, "config.json", `{
+		"discord_token": "token",
+		"command_prefix": "+",
+		"database-dir": "`+dir+`",
+		"developer_id": 123456789012345678,
+		"owner_ids": ["2", 3],
+		"reminder_interval": 500,
+		"cooldowns": ["remind:user:3/1m", "stats:guild:10s"],
+		"workers": 4,
+		"debug": true,
+		"holiday_calendar": "DE",
+		"guild_allowlist_mode": "dormant",
+		"plugin_dir": null
+	}`)
+	defer cleanup()
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.DiscordToken != "token" || config.CommandPrefix != "+" || config.DatabaseDir != dir || config.Path != path {
+		t.Errorf("got %+v", config)
+	}
+	if want := []disgord.Snowflake{123456789012345678, 2, 3}; !reflect.DeepEqual(config.OwnerIDs, want) {
+		t.Errorf("got owners %v, want %v", config.OwnerIDs, want)
+	}
+	if config.ReminderInterval != 500*time.Millisecond || config.Workers != 4 || !config.Debug || !config.DormantGuilds {
+		t.Errorf("got %+v", config)
+	}
+	if config.DefaultHolidayCalendar != "de" || len(config.Cooldowns) != 2 {
+		t.Errorf("got calendar %q and cooldowns %v", config.DefaultHolidayCalendar, config.Cooldowns)
+	}
+	// Unset settings get their defaults.
+	if config.CommandTimeout != 30*time.Second || config.QueueSize != 25 || config.PluginDir != "" {
+		t.Errorf("got %+v", config)
+	}
+}
+
+func TestLoadConfigFormats(t *testing.T) {
+	defer setEnv(nil)()
+
+	dir, err := ioutil.TempDir("", "disgotify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"config.json": `{
+			"discord_token": "token",
+			"command-prefix": "+",
+			"database_dir": "` + dir + `",
+			"owner_ids": [123456789012345678, "2"],
+			"reminder_interval": 500,
+			"cooldowns": ["remind:user:3/1m", "stats:guild:10s"],
+			"maintenance": true
+		}`,
+		"config.yaml": `
+discord_token: token
+command-prefix: "+"
+database_dir: ` + dir + `
+owner_ids: [123456789012345678, "2"]
+reminder_interval: 500
+cooldowns:
+  - remind:user:3/1m
+  - stats:guild:10s
+maintenance: true
+`,
+		"config.toml": `
+discord_token = "token"
+command-prefix = "+"
+database_dir = "` + dir + `"
+owner_ids = [123456789012345678, 2]
+reminder_interval = 500
+cooldowns = ["remind:user:3/1m", "stats:guild:10s"]
+maintenance = true
+`,
+	}
+
+	for name, content := range files {
+		path, cleanup := writeConfig(t, name, content)
+		config, err := LoadConfig(path)
+		cleanup()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		if config.DiscordToken != "token" || config.CommandPrefix != "+" || config.DatabaseDir != dir || !config.Maintenance {
+			t.Errorf("%s: got %+v", name, config)
+		}
+		if want := []disgord.Snowflake{123456789012345678, 2}; !reflect.DeepEqual(config.OwnerIDs, want) {
+			t.Errorf("%s: got owners %v, want %v", name, config.OwnerIDs, want)
+		}
+		if config.ReminderInterval != 500*time.Millisecond || len(config.Cooldowns) != 2 {
+			t.Errorf("%s: got interval %s and cooldowns %v", name, config.ReminderInterval, config.Cooldowns)
+		}
+	}
+}
+
+func TestLoadConfigEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "disgotify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path, cleanup := writeConfig(t, "config.json", `{"discord_token": "file", "command_prefix": "+", "reminder_interval": "1m"}`)
+	defer cleanup()
+
+	defer setEnv(map[string]string{
+		"DISCORD_TOKEN":     "env",
+		"DATABASE_DIR":      dir,
+		"REMINDER_INTERVAL": "",
+		"OWNER_IDS":         "1,2",
+	})()
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.DiscordToken != "env" || config.DatabaseDir != dir || len(config.OwnerIDs) != 2 {
+		t.Errorf("environment variables must override the file, got %+v", config)
+	}
+	if config.CommandPrefix != "+" || config.ReminderInterval != time.Minute {
+		t.Errorf("empty variables must not override the file, got %+v", config)
+	}
+
+	// Without file the environment suffices.
+	os.Setenv("COMMAND_PREFIX", "!")
+	config, err = LoadConfig("")
+	if err != nil || config.CommandPrefix != "!" {
+		t.Errorf("got %+v, %v", config, err)
+	}
+}
+
+func TestLoadConfigProblems(t *testing.T) {
+	defer setEnv(map[string]string{
+		"DATABASE_DIR":         "/nonexistent/disgotify",
+		"REMINDER_INTERVAL":    "soon",
+		"WORKERS":              "0",
+		"OWNER_IDS":            "1,me",
+		"COOLDOWNS":            "remind:user:0/1m",
+		"INTERACTIONS_ADDR":    ":8080",
+		"GUILD_ALLOWLIST_MODE": "ignore",
+		"DEBUG":                "maybe",
+	})()
+
+	config, err := LoadConfig("")
+	configErr, ok := err.(*ConfigError)
+	if !ok {
+		t.Fatalf("got %v, want a *ConfigError", err)
+	}
+
+	for _, want := range []string{
+		"DISCORD_TOKEN is missing",
+		"COMMAND_PREFIX is missing",
+		"DATABASE_DIR: /nonexistent/disgotify is not writable",
+		"REMINDER_INTERVAL \"soon\" is not a positive duration",
+		"WORKERS \"0\" is not a positive number",
+		"OWNER_IDS: invalid IDs: me",
+		"COOLDOWNS:",
+		"DISCORD_PUBLIC_KEY is missing",
+		"GUILD_ALLOWLIST_MODE \"ignore\"",
+		"DEBUG \"maybe\" is not a boolean",
+	} {
+		found := false
+		for _, problem := range configErr.Problems {
+			if strings.HasPrefix(problem, want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("problem %q missing in %q", want, configErr.Problems)
+		}
+	}
+
+	// The config is returned anyway with defaults in place of invalid settings.
+	if config == nil || config.ReminderInterval != 10*time.Second || config.Workers != 8 || len(config.OwnerIDs) != 1 {
+		t.Errorf("got %+v", config)
+	}
+}
+
+func TestLoadConfigFileProblems(t *testing.T) {
+	dir, err := ioutil.TempDir("", "disgotify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer setEnv(map[string]string{"DISCORD_TOKEN": "token", "COMMAND_PREFIX": "+", "DATABASE_DIR": dir})()
+
+	tests := []struct {
+		name    string
+		content string
+		problem string
+	}{
+		{"config.ini", "discord_token = token", "unsupported format \".ini\", use .json, .yaml or .toml"},
+		{"config.json", "{\"discord_token\": ", "unexpected EOF"},
+		{"config.yaml", "discord_token: [token", "did not find expected ',' or ']'"},
+		{"config.toml", "discord_token = ", "unexpected EOF; expected value"},
+		{"config.json", "{\"token\": \"x\", \"prefix\": \"+\"}", "unknown settings prefix, token"},
+		{"config.json", "{\"cooldowns\": {\"remind\": \"user:3/1m\"}}", "cooldowns cannot be an object"},
+		{"config.yml", "cooldowns:\n  remind: user:3/1m", "cooldowns cannot be an object"},
+		{"config.toml", "[cooldowns]\nremind = \"user:3/1m\"", "cooldowns cannot be an object"},
+	}
+
+	for _, test := range tests {
+		path, cleanup := writeConfig(t, test.name, test.content)
+		_, err := LoadConfig(path)
+		cleanup()
+
+		configErr, ok := err.(*ConfigError)
+		if !ok || len(configErr.Problems) != 1 || !strings.HasSuffix(configErr.Problems[0], test.problem) {
+			t.Errorf("%s %q: got %v, want %q", test.name, test.content, err, test.problem)
+		}
+	}
+
+	if _, err := LoadConfig(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("a missing config file must be reported")
+	}
+}
+
+func TestConfigReload(t *testing.T) {
+	current := &Config{Path: "config.json", DiscordToken: "token", CommandPrefix: "!", Workers: 2, Debug: true}
+	next := &Config{Path: "config.json", DiscordToken: "other", CommandPrefix: "+", Workers: 2, Debug: true}
+
+	reloaded, changes := current.Reload(next)
+	if reloaded.CommandPrefix != "+" || reloaded.DiscordToken != "token" {
+		t.Errorf("got prefix %q and token %q, want + and the current token", reloaded.CommandPrefix, reloaded.DiscordToken)
+	}
+	if want := []string{"COMMAND_PREFIX"}; !reflect.DeepEqual(changes.Applied, want) {
+		t.Errorf("applied %v, want %v", changes.Applied, want)
+	}
+	if want := []string{"DISCORD_TOKEN"}; !reflect.DeepEqual(changes.Restart, want) {
+		t.Errorf("restart required for %v, want %v", changes.Restart, want)
+	}
+	if next.DiscordToken != "other" {
+		t.Error("Reload must not modify the next config")
+	}
+
+	if _, changes := current.Reload(current); len(changes.Applied) != 0 || len(changes.Restart) != 0 {
+		t.Errorf("got changes %+v for the same config", changes)
+	}
+}
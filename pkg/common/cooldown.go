@@ -0,0 +1,80 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CooldownScope represents who shares a cooldown.
+type CooldownScope uint
+
+// Cooldown scope.
+const (
+	CooldownUser CooldownScope = iota
+	CooldownChannel
+	CooldownGuild
+)
+
+var cooldownScopeTranslate = map[string]CooldownScope{
+	"user":    CooldownUser,
+	"channel": CooldownChannel,
+	"guild":   CooldownGuild,
+}
+
+// Cooldown represents a limit of command uses per period within a scope.
+type Cooldown struct {
+	Scope  CooldownScope
+	Uses   int
+	Period time.Duration
+}
+
+// ParseCooldowns parses cooldown overrides mapped by command name.
+// Entries are separated by semicolons and have the format "command:scope:[uses/]period",
+// e.g. "list:user:30s;remind:user:5/1m;remind:guild:20/1m".
+func ParseCooldowns(str string) (map[string][]Cooldown, error) {
+	cooldowns := map[string][]Cooldown{}
+
+	for _, entry := range strings.Split(str, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid cooldown \"%s\"", entry)
+		}
+
+		scope, ok := cooldownScopeTranslate[strings.ToLower(parts[1])]
+		if !ok {
+			return nil, fmt.Errorf("invalid cooldown scope \"%s\"", parts[1])
+		}
+
+		uses := 1
+		period := parts[2]
+		if rate := strings.SplitN(period, "/", 2); len(rate) == 2 {
+			n, err := strconv.Atoi(rate[0])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid cooldown uses \"%s\"", rate[0])
+			}
+			uses = n
+			period = rate[1]
+		}
+
+		d, err := time.ParseDuration(period)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid cooldown period \"%s\"", period)
+		}
+
+		name := strings.ToLower(parts[0])
+		cooldowns[name] = append(cooldowns[name], Cooldown{
+			Scope:  scope,
+			Uses:   uses,
+			Period: d,
+		})
+	}
+
+	return cooldowns, nil
+}
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseCooldowns(t *testing.T) {
+	cooldowns, err := ParseCooldowns(" list:user:30s; remind:USER:5/1m;remind:guild:20/1m;")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]Cooldown{
+		"list": {{Scope: CooldownUser, Uses: 1, Period: 30 * time.Second}},
+		"remind": {
+			{Scope: CooldownUser, Uses: 5, Period: time.Minute},
+			{Scope: CooldownGuild, Uses: 20, Period: time.Minute},
+		},
+	}
+	if !reflect.DeepEqual(cooldowns, want) {
+		t.Errorf("ParseCooldowns = %v, want %v", cooldowns, want)
+	}
+}
+
+func TestParseCooldownsErrors(t *testing.T) {
+	for _, str := range []string{
+		"list:30s",
+		"list:server:30s",
+		"list:user:soon",
+		"list:user:0s",
+		"list:user:-5s",
+		"list:user:0/1m",
+		"list:user:-1/1m",
+		"list:user:five/1m",
+	} {
+		if _, err := ParseCooldowns(str); err == nil {
+			t.Errorf("ParseCooldowns(%q) must fail", str)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package common
+
+import (
+	"github.com/andersfylling/disgord"
+	"github.com/jinzhu/gorm"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+// GetCustomCommand returns the custom command of a guild by name or nil.
+func GetCustomCommand(guildID disgord.Snowflake, name string) (*models.CustomCommand, error) {
+	if guildID.Empty() {
+		return nil, nil
+	}
+
+	command := &models.CustomCommand{}
+	err := DB.Where(models.CustomCommand{
+		GuildID: guildID,
+		Name:    name,
+	}).First(command).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return command, nil
+}
+
+// GetCustomCommands returns all custom commands of a guild ordered by name.
+func GetCustomCommands(guildID disgord.Snowflake) ([]models.CustomCommand, error) {
+	var customCommands []models.CustomCommand
+	err := DB.Where(models.CustomCommand{
+		GuildID: guildID,
+	}).Order("name").Find(&customCommands).Error
+	return customCommands, err
+}
@@ -1,9 +1,12 @@
 package common
 
 import (
+	"context"
+	"fmt"
 	"path"
 	"time"
 
+	"github.com/andersfylling/disgord"
 	"github.com/jinzhu/gorm"
 	"github.com/qysp/disgotify/pkg/models"
 
@@ -14,15 +17,26 @@ import (
 // DB represents the disgotify database.
 var DB *gorm.DB
 
-// Init opens a connection to the database and auto migrates the models.
+// InitDB opens a connection to the database in dir and auto migrates the models.
 // Panics if there was an error initializing the database connection.
-func InitDB() {
-	db, err := gorm.Open("sqlite3", path.Join(DatabaseDir, "disgotify.db"))
+func InitDB(dir string) {
+	db, err := gorm.Open("sqlite3", path.Join(dir, "disgotify.db"))
 	if err != nil {
 		Logger.Fatal(err)
 	}
 
-	db.AutoMigrate(&models.Reminder{})
+	db.AutoMigrate(
+		&models.Reminder{},
+		&models.GuildSettings{},
+		&models.GuildCommand{},
+		&models.Alias{},
+		&models.CustomCommand{},
+		&models.CommandStat{},
+		&models.ReminderStat{},
+		&models.GuildRole{},
+		&models.CommandRule{},
+		&models.BlockedUser{},
+	)
 
 	DB = db
 
@@ -32,6 +46,20 @@ func InitDB() {
 	}
 }
 
+// Transaction runs fn in a transaction bound to ctx, which is rolled back if fn fails or ctx is done.
+func Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	tx := DB.BeginTx(ctx, nil)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
 // cleanReminders deletes outdated reminders in case the bot was down for a period of time.
 // TODO: Add an exception for repeating reminders.
 func cleanReminders() error {
@@ -54,3 +82,13 @@ func cleanReminders() error {
 
 	return nil
 }
+
+// DeleteGuildReminders deletes all reminders created in a guild and returns their number.
+// An empty guild ID is rejected, DM reminders are never deleted with it.
+func DeleteGuildReminders(guildID disgord.Snowflake) (int64, error) {
+	if guildID.Empty() {
+		return 0, fmt.Errorf("cannot delete the reminders of an empty guild ID")
+	}
+	db := DB.Unscoped().Where("guild_id = ?", guildID).Delete(&models.Reminder{})
+	return db.RowsAffected, db.Error
+}
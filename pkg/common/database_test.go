@@ -0,0 +1,93 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andersfylling/disgord"
+	"github.com/jinzhu/gorm"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+func TestTransaction(t *testing.T) {
+	count := func() int {
+		var n int
+		if err := DB.Model(&models.Alias{}).Where("name = ?", "tx").Count(&n).Error; err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	failure := errors.New("failure")
+	err := Transaction(context.Background(), func(tx *gorm.DB) error {
+		if err := tx.Create(&models.Alias{UserID: 1, Name: "tx", Expansion: "ping"}).Error; err != nil {
+			return err
+		}
+		return failure
+	})
+	if err != failure || count() != 0 {
+		t.Errorf("got %v and %d rows, want the failure and a rollback", err, count())
+	}
+
+	err = Transaction(context.Background(), func(tx *gorm.DB) error {
+		return tx.Create(&models.Alias{UserID: 1, Name: "tx", Expansion: "ping"}).Error
+	})
+	if err != nil || count() != 1 {
+		t.Errorf("got %v and %d rows, want a commit", err, count())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	err = Transaction(ctx, func(tx *gorm.DB) error {
+		called = true
+		return nil
+	})
+	if err == nil || called {
+		t.Errorf("a done context must not start a transaction, got %v", err)
+	}
+}
+
+func TestGetStatsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := GetCommandStats(ctx, time.Now()); err == nil {
+		t.Error("GetCommandStats must fail once the context is done")
+	}
+	if _, err := GetReminderStats(ctx, time.Now()); err == nil {
+		t.Error("GetReminderStats must fail once the context is done")
+	}
+
+	if _, err := GetCommandStats(context.Background(), time.Now()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDeleteGuildReminders(t *testing.T) {
+	const guild, other = disgord.Snowflake(800), disgord.Snowflake(801)
+	for _, guildID := range []disgord.Snowflake{guild, guild, other, 0} {
+		if err := DB.Create(&models.Reminder{UserID: 2, GuildID: guildID, Due: 1 << 40}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer DB.Unscoped().Where("guild_id IN (?)", []disgord.Snowflake{guild, other, 0}).Delete(&models.Reminder{})
+
+	count := func(guildID disgord.Snowflake) int {
+		var n int
+		DB.Model(&models.Reminder{}).Where("guild_id = ?", guildID).Count(&n)
+		return n
+	}
+
+	if _, err := DeleteGuildReminders(0); err == nil {
+		t.Error("an empty guild ID must be rejected")
+	}
+	if deleted, err := DeleteGuildReminders(guild); err != nil || deleted != 2 {
+		t.Errorf("got %d deleted and %v, want 2", deleted, err)
+	}
+	if count(guild) != 0 || count(other) != 1 || count(0) != 1 {
+		t.Errorf("got %d, %d and %d DM reminders left, want 0, 1 and 1", count(guild), count(other), count(0))
+	}
+}
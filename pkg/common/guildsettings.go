@@ -0,0 +1,121 @@
+package common
+
+import (
+	"sync"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+// GetGuildSettings returns the settings of a guild.
+// If the guild has no stored settings yet, unsaved defaults are returned.
+func GetGuildSettings(guildID disgord.Snowflake) (*models.GuildSettings, error) {
+	settings := &models.GuildSettings{}
+	err := DB.Where(models.GuildSettings{
+		GuildID: guildID,
+	}).FirstOrInit(settings).Error
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// SaveGuildSettings creates or updates the settings of a guild.
+func SaveGuildSettings(settings *models.GuildSettings) error {
+	err := DB.Save(settings).Error
+
+	guildPrefixesMu.Lock()
+	delete(guildPrefixes, settings.GuildID)
+	guildPrefixesMu.Unlock()
+
+	return err
+}
+
+var (
+	// guildPrefixes caches the prefixes of guilds since they are needed for every message, empty if not set.
+	guildPrefixes   = map[disgord.Snowflake]string{}
+	guildPrefixesMu sync.RWMutex
+)
+
+// GuildPrefix returns the command prefix of a guild, falling back to the configured default prefix.
+func GuildPrefix(guildID disgord.Snowflake, defaultPrefix string) string {
+	if guildID.Empty() {
+		return defaultPrefix
+	}
+
+	guildPrefixesMu.RLock()
+	prefix, ok := guildPrefixes[guildID]
+	guildPrefixesMu.RUnlock()
+
+	if !ok {
+		settings, err := GetGuildSettings(guildID)
+		if err != nil {
+			Logger.Error(err)
+			return defaultPrefix
+		}
+		prefix = settings.Prefix
+
+		guildPrefixesMu.Lock()
+		guildPrefixes[guildID] = prefix
+		guildPrefixesMu.Unlock()
+	}
+
+	if prefix == "" {
+		return defaultPrefix
+	}
+	return prefix
+}
+
+// GuildHolidayCalendar returns the holiday calendar selected by a guild,
+// falling back to the configured default calendar.
+func GuildHolidayCalendar(guildID disgord.Snowflake, defaultCalendar string) string {
+	if guildID.Empty() {
+		return defaultCalendar
+	}
+
+	settings, err := GetGuildSettings(guildID)
+	if err != nil {
+		Logger.Error(err)
+		return defaultCalendar
+	}
+	if settings.HolidayCalendar == "" {
+		return defaultCalendar
+	}
+	return settings.HolidayCalendar
+}
+
+// GetGuildCommand returns the configuration of a command in a guild.
+// If the command has no stored configuration yet, an unsaved default is returned.
+func GetGuildCommand(guildID disgord.Snowflake, command string) (*models.GuildCommand, error) {
+	config := &models.GuildCommand{}
+	err := DB.Where(models.GuildCommand{
+		GuildID: guildID,
+		Command: command,
+	}).FirstOrInit(config).Error
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// GetGuildCommands returns all stored command configurations of a guild.
+func GetGuildCommands(guildID disgord.Snowflake) ([]models.GuildCommand, error) {
+	var configs []models.GuildCommand
+	err := DB.Where(models.GuildCommand{
+		GuildID: guildID,
+	}).Order("command").Find(&configs).Error
+	return configs, err
+}
+
+// SaveGuildCommand creates or updates the configuration of a command in a guild.
+func SaveGuildCommand(config *models.GuildCommand) error {
+	return DB.Save(config).Error
+}
+
+// DeleteGuildCommand removes the configuration of a command in a guild, which enables it everywhere.
+func DeleteGuildCommand(config *models.GuildCommand) error {
+	if config.ID == 0 {
+		return nil
+	}
+	return DB.Unscoped().Delete(config).Error
+}
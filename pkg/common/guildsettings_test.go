@@ -0,0 +1,65 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/andersfylling/disgord"
+)
+
+func TestGuildPrefix(t *testing.T) {
+	const guildID = disgord.Snowflake(100)
+
+	if prefix := GuildPrefix(guildID, "+"); prefix != "+" {
+		t.Errorf("guild without settings got %q, want the default", prefix)
+	}
+	if prefix := GuildPrefix(0, "+"); prefix != "+" {
+		t.Errorf("DM got %q, want the default", prefix)
+	}
+
+	settings, err := GetGuildSettings(guildID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.Prefix = "!"
+	if err := SaveGuildSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if prefix := GuildPrefix(guildID, "+"); prefix != "!" {
+		t.Errorf("got %q after setting the prefix, want \"!\"", prefix)
+	}
+	if prefix := GuildPrefix(guildID+1, "+"); prefix != "+" {
+		t.Errorf("other guild got %q, want the default", prefix)
+	}
+
+	settings.Prefix = ""
+	if err := SaveGuildSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if prefix := GuildPrefix(guildID, "+"); prefix != "+" {
+		t.Errorf("got %q after resetting the prefix, want the default", prefix)
+	}
+}
+
+func TestMessageStateGuildPrefix(t *testing.T) {
+	const guildID = disgord.Snowflake(101)
+
+	settings, err := GetGuildSettings(guildID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.Prefix = "!!"
+	if err := SaveGuildSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newDMState("!!remind tomorrow")
+	s.Event.Message.GuildID = guildID
+	if command := s.UserCommand(); command != "remind" {
+		t.Errorf("UserCommand() = %q, want \"remind\"", command)
+	}
+
+	s.Event.Message.Content = "+remind tomorrow"
+	if s.HasPrefix() {
+		t.Error("the default prefix must not be accepted in a guild with its own prefix")
+	}
+}
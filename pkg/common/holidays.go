@@ -0,0 +1,465 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Holiday represents a single (possibly multi-day) holiday event of a calendar.
+type Holiday struct {
+	Summary string
+	Start   time.Time
+	// End is exclusive, as defined by the iCalendar specification for date values.
+	End time.Time
+	// Rule is the yearly recurrence of the holiday, nil if it happens once.
+	Rule *YearlyRule
+}
+
+// YearlyRule represents the supported subset of a yearly iCalendar recurrence rule (RRULE).
+type YearlyRule struct {
+	Interval  int
+	Months    []time.Month
+	MonthDays []int
+	Weekdays  []RuleWeekday
+	// Until is the last day the holiday may start on, zero if unlimited.
+	Until time.Time
+	// Count is the number of occurrences, zero if unlimited.
+	Count int
+}
+
+// RuleWeekday represents a BYDAY entry such as MO, 4TH or -1MO.
+type RuleWeekday struct {
+	// Ordinal is the occurrence of the weekday in the month, negative values count from its end, zero means every.
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// HolidayCalendar represents a named list of holidays loaded from an iCalendar file.
+type HolidayCalendar struct {
+	Name     string
+	Holidays []Holiday
+}
+
+// Holidays represents all loaded holiday calendars mapped by their name.
+var Holidays = map[string]*HolidayCalendar{}
+
+// InitHolidays loads all iCalendar (.ics) files from dir.
+// The calendar name is the file name without its extension.
+func InitHolidays(dir string) {
+	if dir == "" {
+		return
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		Logger.Error(err)
+		return
+	}
+
+	for _, file := range files {
+		if file.IsDir() || strings.ToLower(filepath.Ext(file.Name())) != ".ics" {
+			continue
+		}
+
+		cal, warnings, err := loadHolidayCalendar(filepath.Join(dir, file.Name()))
+		if err != nil {
+			Logger.Error(err)
+			continue
+		}
+		for _, warning := range warnings {
+			Logger.Warn(file.Name(), warning)
+		}
+
+		Holidays[cal.Name] = cal
+		Logger.Info("Loaded holiday calendar", cal.Name, "with", len(cal.Holidays), "holidays")
+	}
+}
+
+// HolidayCalendarNames returns the sorted names of all loaded holiday calendars.
+func HolidayCalendarNames() []string {
+	var names []string
+	for name := range Holidays {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsHoliday returns a bool which indicates whether the date of t is a holiday in the named calendar.
+// Unknown calendars never have holidays.
+func IsHoliday(calendar string, t time.Time) bool {
+	cal, ok := Holidays[calendar]
+	if !ok {
+		return false
+	}
+
+	day := truncateDay(t)
+	for _, holiday := range cal.Holidays {
+		if holiday.On(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// On returns a bool which indicates whether the holiday takes place on the day, which must be midnight in the local timezone.
+func (h Holiday) On(day time.Time) bool {
+	if h.Rule == nil {
+		return !day.Before(h.Start) && day.Before(h.End)
+	}
+
+	// Events of the previous year may last into this one, e.g. from the 31st of December to the 2nd of January.
+	days := int(h.End.Sub(h.Start).Hours()/24 + 0.5)
+	for year := day.Year() - 1; year <= day.Year(); year++ {
+		for _, start := range h.Occurrences(year) {
+			if !day.Before(start) && day.Before(start.AddDate(0, 0, days)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Occurrences returns the start dates of the holiday in the year in chronological order.
+func (h Holiday) Occurrences(year int) []time.Time {
+	if h.Rule == nil {
+		if h.Start.Year() == year {
+			return []time.Time{h.Start}
+		}
+		return nil
+	}
+	if year < h.Start.Year() {
+		return nil
+	}
+
+	var count int
+	if h.Rule.Count > 0 {
+		// The count applies to all occurrences since the start, so the previous years have to be expanded too.
+		for y := h.Start.Year(); y < year; y++ {
+			count += len(h.Rule.expand(h.Start, y))
+		}
+	}
+
+	var dates []time.Time
+	for _, date := range h.Rule.expand(h.Start, year) {
+		if h.Rule.Count > 0 && count >= h.Rule.Count {
+			break
+		}
+		count++
+		dates = append(dates, date)
+	}
+	return dates
+}
+
+// expand returns the dates of the rule in the year, starting from the first occurrence at start.
+func (r *YearlyRule) expand(start time.Time, year int) []time.Time {
+	if (year-start.Year())%r.Interval != 0 {
+		return nil
+	}
+
+	months := r.Months
+	if len(months) == 0 {
+		months = []time.Month{start.Month()}
+	}
+
+	var dates []time.Time
+	for _, month := range months {
+		for _, date := range r.monthDates(start, year, month) {
+			if date.Before(start) || (!r.Until.IsZero() && date.After(r.Until)) {
+				continue
+			}
+			dates = append(dates, date)
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	return dates
+}
+
+// monthDates returns the days of the month matching the rule.
+func (r *YearlyRule) monthDates(start time.Time, year int, month time.Month) []time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+	length := first.AddDate(0, 1, -1).Day()
+
+	monthDays := map[int]bool{}
+	for _, day := range r.MonthDays {
+		if day < 0 {
+			day = length + day + 1
+		}
+		monthDays[day] = true
+	}
+
+	var dates []time.Time
+	for day := 1; day <= length; day++ {
+		date := first.AddDate(0, 0, day-1)
+		switch {
+		case len(r.Weekdays) > 0:
+			if !r.matchesWeekday(date, length) || (len(monthDays) > 0 && !monthDays[day]) {
+				continue
+			}
+		case len(monthDays) > 0:
+			if !monthDays[day] {
+				continue
+			}
+		case day != start.Day():
+			// Without BYDAY and BYMONTHDAY the day of the start is used, months without it are skipped (e.g. the 29th of February).
+			continue
+		}
+		dates = append(dates, date)
+	}
+	return dates
+}
+
+// matchesWeekday returns a bool which indicates whether the date matches one of the weekdays of the rule within its month.
+func (r *YearlyRule) matchesWeekday(date time.Time, length int) bool {
+	for _, weekday := range r.Weekdays {
+		if date.Weekday() != weekday.Weekday {
+			continue
+		}
+		switch {
+		case weekday.Ordinal == 0:
+			return true
+		case weekday.Ordinal > 0 && (date.Day()-1)/7+1 == weekday.Ordinal:
+			return true
+		case weekday.Ordinal < 0 && (length-date.Day())/7+1 == -weekday.Ordinal:
+			return true
+		}
+	}
+	return false
+}
+
+// IsWeekday returns a bool which indicates whether t is on a day from monday to friday.
+func IsWeekday(t time.Time) bool {
+	return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+}
+
+// IsBusinessDay returns a bool which indicates whether t is a weekday which is not a holiday in the named calendar.
+func IsBusinessDay(calendar string, t time.Time) bool {
+	return IsWeekday(t) && !IsHoliday(calendar, t)
+}
+
+// NextBusinessDay returns t moved forward by at least one day until it is on a business day.
+// The time of day is kept.
+func NextBusinessDay(calendar string, t time.Time) time.Time {
+	t = t.AddDate(0, 0, 1)
+	// A year of consecutive holidays means the calendar is broken, bail out instead of looping forever.
+	for i := 0; i < 366 && !IsBusinessDay(calendar, t); i++ {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// loadHolidayCalendar parses the VEVENT components of an iCalendar file.
+// Only the properties needed for holidays are supported: DTSTART, DTEND, SUMMARY and a yearly RRULE.
+// Events with unsupported recurrence rules only take place on their start date, a warning is returned for each.
+func loadHolidayCalendar(file string) (*HolidayCalendar, []string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	cal := &HolidayCalendar{
+		Name: strings.ToLower(name),
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		// Long lines are folded by starting the continuation with a whitespace.
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var warnings []string
+	var holiday *Holiday
+	var rrule string
+	for _, line := range lines {
+		sep := strings.Index(line, ":")
+		if sep < 0 {
+			continue
+		}
+		// Strip parameters such as DTSTART;VALUE=DATE.
+		prop := strings.ToUpper(strings.SplitN(line[:sep], ";", 2)[0])
+		value := line[sep+1:]
+
+		switch {
+		case prop == "BEGIN" && value == "VEVENT":
+			holiday = &Holiday{}
+			rrule = ""
+		case prop == "END" && value == "VEVENT" && holiday != nil:
+			if !holiday.Start.IsZero() {
+				if holiday.End.IsZero() || !holiday.End.After(holiday.Start) {
+					holiday.End = holiday.Start.AddDate(0, 0, 1)
+				}
+				if rrule != "" {
+					rule, err := parseYearlyRule(rrule)
+					if err != nil {
+						warnings = append(warnings, fmt.Sprintf("%s (%s): %s, it only takes place on its start date", holiday.Summary, rrule, err.Error()))
+					}
+					holiday.Rule = rule
+				}
+				cal.Holidays = append(cal.Holidays, *holiday)
+			}
+			holiday = nil
+		case holiday == nil:
+			continue
+		case prop == "SUMMARY":
+			holiday.Summary = value
+		case prop == "DTSTART":
+			holiday.Start = parseICalDate(value)
+		case prop == "DTEND":
+			holiday.End = parseICalDate(value)
+		case prop == "RRULE":
+			rrule = value
+		}
+	}
+
+	return cal, warnings, nil
+}
+
+// parseYearlyRule parses a recurrence rule, only yearly rules with BYMONTH, BYMONTHDAY and BYDAY are supported.
+func parseYearlyRule(value string) (*YearlyRule, error) {
+	rule := &YearlyRule{Interval: 1}
+	var yearly bool
+	for _, part := range strings.Split(strings.ToUpper(value), ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("malformed rule part \"%s\"", part)
+		}
+		var err error
+		switch kv[0] {
+		case "FREQ":
+			if kv[1] != "YEARLY" {
+				return nil, fmt.Errorf("unsupported frequency %s", kv[1])
+			}
+			yearly = true
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(kv[1])
+			if err == nil && rule.Interval < 1 {
+				err = fmt.Errorf("interval must be positive")
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(kv[1])
+		case "UNTIL":
+			rule.Until = parseICalDate(kv[1])
+			if rule.Until.IsZero() {
+				err = fmt.Errorf("invalid date \"%s\"", kv[1])
+			}
+		case "BYMONTH":
+			err = parseRuleList(kv[1], func(s string) error {
+				month, err := strconv.Atoi(s)
+				if err != nil || month < 1 || month > 12 {
+					return fmt.Errorf("invalid month \"%s\"", s)
+				}
+				rule.Months = append(rule.Months, time.Month(month))
+				return nil
+			})
+		case "BYMONTHDAY":
+			err = parseRuleList(kv[1], func(s string) error {
+				day, err := strconv.Atoi(s)
+				if err != nil || day == 0 || day < -31 || day > 31 {
+					return fmt.Errorf("invalid day of the month \"%s\"", s)
+				}
+				rule.MonthDays = append(rule.MonthDays, day)
+				return nil
+			})
+		case "BYDAY":
+			err = parseRuleList(kv[1], func(s string) error {
+				weekday, err := parseRuleWeekday(s)
+				rule.Weekdays = append(rule.Weekdays, weekday)
+				return err
+			})
+		case "WKST":
+			// Only relevant for weekly rules and BYWEEKNO.
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s", kv[0])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !yearly {
+		return nil, fmt.Errorf("missing frequency")
+	}
+	// Without a month an ordinal weekday counts through the whole year, which is not supported.
+	if len(rule.Weekdays) > 0 && len(rule.Months) == 0 {
+		return nil, fmt.Errorf("BYDAY requires BYMONTH")
+	}
+	return rule, nil
+}
+
+// parseRuleList calls parse for every comma separated value.
+func parseRuleList(value string, parse func(string) error) error {
+	for _, s := range strings.Split(value, ",") {
+		if err := parse(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ruleWeekdays maps the iCalendar weekday abbreviations.
+var ruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// parseRuleWeekday parses a BYDAY entry such as MO, 4TH or -1MO.
+func parseRuleWeekday(value string) (RuleWeekday, error) {
+	if len(value) < 2 {
+		return RuleWeekday{}, fmt.Errorf("invalid weekday \"%s\"", value)
+	}
+	weekday, ok := ruleWeekdays[value[len(value)-2:]]
+	if !ok {
+		return RuleWeekday{}, fmt.Errorf("invalid weekday \"%s\"", value)
+	}
+
+	var ordinal int
+	if prefix := value[:len(value)-2]; prefix != "" {
+		var err error
+		ordinal, err = strconv.Atoi(prefix)
+		if err != nil || ordinal == 0 || ordinal < -5 || ordinal > 5 {
+			return RuleWeekday{}, fmt.Errorf("invalid weekday \"%s\"", value)
+		}
+	}
+	return RuleWeekday{Ordinal: ordinal, Weekday: weekday}, nil
+}
+
+// parseICalDate parses the date part of an iCalendar DATE or DATE-TIME value in the local timezone.
+func parseICalDate(value string) time.Time {
+	if len(value) < 8 {
+		return time.Time{}
+	}
+	t, err := time.ParseInLocation("20060102", value[:8], time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// truncateDay returns midnight of t's date in the local timezone.
+func truncateDay(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
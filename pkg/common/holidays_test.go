@@ -0,0 +1,179 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+func loadTestCalendar(t *testing.T, file string) *HolidayCalendar {
+	t.Helper()
+	cal, warnings, err := loadHolidayCalendar(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) > 0 {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+	return cal
+}
+
+func TestIsHoliday(t *testing.T) {
+	cal := loadTestCalendar(t, filepath.Join("testdata", "usholidays.ics"))
+	Holidays = map[string]*HolidayCalendar{cal.Name: cal}
+	defer func() { Holidays = map[string]*HolidayCalendar{} }()
+
+	tests := []struct {
+		name    string
+		day     time.Time
+		holiday bool
+	}{
+		{"new year", date(2025, time.January, 1), true},
+		{"mlk day", date(2025, time.January, 20), true},
+		{"memorial day 2024", date(2024, time.May, 27), true},
+		{"memorial day 2025", date(2025, time.May, 26), true},
+		{"memorial day start date in other year", date(2025, time.May, 31), false},
+		{"monday before memorial day", date(2025, time.May, 19), false},
+		{"juneteenth", date(2024, time.June, 19), true},
+		{"juneteenth before first occurrence", date(2020, time.June, 19), false},
+		{"independence day", date(2024, time.July, 4), true},
+		{"labor day", date(2026, time.September, 7), true},
+		{"thanksgiving 2023", date(2023, time.November, 23), true},
+		{"thanksgiving 2024", date(2024, time.November, 28), true},
+		{"thanksgiving start date in other year", date(2024, time.November, 25), false},
+		{"christmas", date(2026, time.December, 25), true},
+		{"inauguration day", date(2025, time.January, 20), true},
+		{"no inauguration", date(2027, time.January, 20), false},
+		{"ordinary day", date(2025, time.March, 12), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsHoliday("usholidays", test.day.Add(15*time.Hour)); got != test.holiday {
+				t.Errorf("IsHoliday(%s) = %v, want %v", test.day.Format("2006-01-02"), got, test.holiday)
+			}
+		})
+	}
+}
+
+func TestNextBusinessDay(t *testing.T) {
+	cal := loadTestCalendar(t, filepath.Join("testdata", "usholidays.ics"))
+	Holidays = map[string]*HolidayCalendar{cal.Name: cal}
+	defer func() { Holidays = map[string]*HolidayCalendar{} }()
+
+	tests := []struct {
+		from time.Time
+		want time.Time
+	}{
+		// Friday before Memorial Day.
+		{date(2025, time.May, 23), date(2025, time.May, 27)},
+		// Wednesday before Thanksgiving.
+		{date(2024, time.November, 27), date(2024, time.November, 29)},
+		// Christmas Eve on a Tuesday.
+		{date(2024, time.December, 24), date(2024, time.December, 26)},
+	}
+
+	for _, test := range tests {
+		from := test.from.Add(9 * time.Hour)
+		if got := NextBusinessDay("usholidays", from); !got.Equal(test.want.Add(9 * time.Hour)) {
+			t.Errorf("NextBusinessDay(%s) = %s, want %s", test.from.Format("2006-01-02"), got.Format("2006-01-02"), test.want.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestHolidayAcrossNewYear(t *testing.T) {
+	holiday := Holiday{
+		Start: date(2019, time.December, 31),
+		End:   date(2020, time.January, 3),
+		Rule:  &YearlyRule{Interval: 1},
+	}
+
+	tests := []struct {
+		day     time.Time
+		holiday bool
+	}{
+		{date(2024, time.December, 30), false},
+		{date(2024, time.December, 31), true},
+		{date(2025, time.January, 1), true},
+		{date(2025, time.January, 2), true},
+		{date(2025, time.January, 3), false},
+		{date(2019, time.January, 1), false},
+	}
+
+	for _, test := range tests {
+		if got := holiday.On(test.day); got != test.holiday {
+			t.Errorf("On(%s) = %v, want %v", test.day.Format("2006-01-02"), got, test.holiday)
+		}
+	}
+}
+
+func TestParseYearlyRule(t *testing.T) {
+	tests := []struct {
+		rule  string
+		valid bool
+	}{
+		{"FREQ=YEARLY", true},
+		{"FREQ=YEARLY;BYMONTH=5;BYDAY=-1MO", true},
+		{"FREQ=YEARLY;INTERVAL=2;COUNT=3;BYMONTH=1,7;BYMONTHDAY=1,-1", true},
+		{"FREQ=YEARLY;UNTIL=20201231T000000Z", true},
+		{"FREQ=MONTHLY", false},
+		{"FREQ=YEARLY;BYDAY=20MO", false},
+		{"FREQ=YEARLY;BYWEEKNO=20", false},
+		{"FREQ=YEARLY;BYMONTH=13", false},
+		{"FREQ=YEARLY;BYMONTH=5;BYDAY=6MO", false},
+		{"BYMONTH=5", false},
+	}
+
+	for _, test := range tests {
+		_, err := parseYearlyRule(test.rule)
+		if (err == nil) != test.valid {
+			t.Errorf("parseYearlyRule(%q) error = %v, want valid %v", test.rule, err, test.valid)
+		}
+	}
+}
+
+func TestOccurrencesCount(t *testing.T) {
+	rule, err := parseYearlyRule("FREQ=YEARLY;COUNT=2;BYMONTH=3;BYMONTHDAY=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	holiday := Holiday{Start: date(2020, time.March, 1), End: date(2020, time.March, 2), Rule: rule}
+
+	for year, want := range map[int]int{2019: 0, 2020: 1, 2021: 1, 2022: 0} {
+		if got := len(holiday.Occurrences(year)); got != want {
+			t.Errorf("Occurrences(%d) returned %d dates, want %d", year, got, want)
+		}
+	}
+}
+
+func TestLoadHolidayCalendarUnsupportedRule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "holidays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "Custom.ics")
+	ics := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY:Week 20\r\nRRULE:FREQ=YEARLY;BYWEEKNO=20\r\n" +
+		"DTSTART;VALUE=DATE:20200511\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	if err := ioutil.WriteFile(file, []byte(ics), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cal, warnings, err := loadHolidayCalendar(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cal.Name != "custom" || len(cal.Holidays) != 1 || len(warnings) != 1 {
+		t.Fatalf("got calendar %q with %d holidays and warnings %v", cal.Name, len(cal.Holidays), warnings)
+	}
+	if !cal.Holidays[0].On(date(2020, time.May, 11)) || cal.Holidays[0].On(date(2021, time.May, 17)) {
+		t.Error("an unsupported rule must only take place on its start date")
+	}
+}
@@ -21,6 +21,9 @@ var (
 
 	// DisGordLogger represents a clone of Logger with a few specifications for Disgord.
 	DisGordLogger *logger.LoggerZap
+
+	// logLevel is the level of both loggers, it can be changed while running.
+	logLevel zap.AtomicLevel
 )
 
 // InitLogger initializes the global logger.
@@ -38,6 +41,8 @@ func InitLogger(debug bool) {
 		conf.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
 	}
 
+	logLevel = conf.Level
+
 	writeSyncer := zapcore.AddSync(io.Writer(file))
 	logger, _ := conf.Build(
 		zap.ErrorOutput(writeSyncer),
@@ -52,6 +57,24 @@ func InitLogger(debug bool) {
 		zap.String("ver", constant.Version)))
 }
 
+// InitNopLogger initializes the global loggers without any output, e.g. for tests.
+func InitNopLogger() {
+	logLevel = zap.NewAtomicLevel()
+	Logger = &GlobalLogger{
+		instance: zap.NewNop(),
+	}
+	DisGordLogger = disgord.DefaultLoggerWithInstance(zap.NewNop())
+}
+
+// SetDebug changes the level of the loggers to DebugLevel or back to InfoLevel.
+func SetDebug(debug bool) {
+	if debug {
+		logLevel.SetLevel(zap.DebugLevel)
+	} else {
+		logLevel.SetLevel(zap.InfoLevel)
+	}
+}
+
 // getMessage is a slightly modified version of DisGord's logging wrapper for zap.
 // All credit goes to its contributors.
 func (l *GlobalLogger) getMessage(v ...interface{}) string {
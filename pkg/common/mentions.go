@@ -0,0 +1,66 @@
+package common
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/andersfylling/disgord"
+	"github.com/andersfylling/disgord/httd"
+)
+
+// AllowedMentions represents the kinds of mentions in a message which notify their targets.
+type AllowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+// UserMentionsOnly lets mentions of users notify, but neither @everyone, @here nor roles.
+var UserMentionsOnly = &AllowedMentions{Parse: []string{"users"}}
+
+// RestrictedSender is implemented by sessions which send messages with allowed mentions themselves.
+type RestrictedSender interface {
+	SendRestrictedMsg(channelID disgord.Snowflake, content string, mentions *AllowedMentions) (*disgord.Message, error)
+}
+
+// restrictedMessage represents a message with allowed mentions, which Disgord's CreateMessageParams lack.
+type restrictedMessage struct {
+	Content         string           `json:"content"`
+	AllowedMentions *AllowedMentions `json:"allowed_mentions"`
+}
+
+// mentionEscaper breaks mentions of @everyone, @here and roles with a zero-width space.
+var mentionEscaper = strings.NewReplacer(
+	"@everyone", "@\u200beveryone",
+	"@here", "@\u200bhere",
+	"<@&", "<@\u200b&",
+)
+
+// SendRestricted sends content to a channel where only user mentions notify their targets.
+// Sessions which cannot send allowed mentions get the other mentions escaped instead.
+func SendRestricted(session disgord.Session, channelID disgord.Snowflake, content string) (*disgord.Message, error) {
+	switch s := session.(type) {
+	case RestrictedSender:
+		return s.SendRestrictedMsg(channelID, content, UserMentionsOnly)
+	case interface{ Req() httd.Requester }:
+		_, body, err := s.Req().Request(&httd.Request{
+			Method: http.MethodPost,
+			// Shares the rate limit bucket of Disgord's CreateMessage.
+			Ratelimiter: "c:" + channelID.String() + ":m",
+			Endpoint:    "/channels/" + channelID.String() + "/messages",
+			Body: &restrictedMessage{
+				Content:         content,
+				AllowedMentions: UserMentionsOnly,
+			},
+			ContentType: httd.ContentTypeJSON,
+		})
+		if err != nil {
+			return nil, err
+		}
+		msg := &disgord.Message{}
+		if err := json.Unmarshal(body, msg); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+	return session.SendMsg(channelID, mentionEscaper.Replace(content))
+}
@@ -0,0 +1,94 @@
+package common
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/andersfylling/disgord"
+	"github.com/andersfylling/disgord/httd"
+)
+
+// requester records the REST requests sent through it.
+type requester struct {
+	httd.Requester
+	requests []*httd.Request
+}
+
+func (r *requester) Request(req *httd.Request) (*http.Response, []byte, error) {
+	r.requests = append(r.requests, req)
+	return &http.Response{StatusCode: http.StatusOK}, []byte(`{"id": "5", "content": "sent"}`), nil
+}
+
+// restSession is a session with a REST requester, like Disgord's client.
+type restSession struct {
+	disgord.Session
+	req *requester
+}
+
+func (s *restSession) Req() httd.Requester {
+	return s.req
+}
+
+// sendSession records the contents sent with SendMsg.
+type sendSession struct {
+	disgord.Session
+	contents []string
+}
+
+func (s *sendSession) SendMsg(channelID disgord.Snowflake, data ...interface{}) (*disgord.Message, error) {
+	s.contents = append(s.contents, data[0].(string))
+	return &disgord.Message{}, nil
+}
+
+func TestSendRestrictedREST(t *testing.T) {
+	session := &restSession{req: &requester{}}
+
+	msg, err := SendRestricted(session, 200, "@everyone <@&300> hi <@2>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.ID != 5 {
+		t.Errorf("got message %+v, want the decoded response", msg)
+	}
+
+	if len(session.req.requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(session.req.requests))
+	}
+	req := session.req.requests[0]
+	if req.Method != http.MethodPost || req.Endpoint != "/channels/200/messages" {
+		t.Errorf("got %s %s", req.Method, req.Endpoint)
+	}
+
+	body, err := json.Marshal(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent struct {
+		Content         string `json:"content"`
+		AllowedMentions struct {
+			Parse []string `json:"parse"`
+		} `json:"allowed_mentions"`
+	}
+	if err := json.Unmarshal(body, &sent); err != nil {
+		t.Fatal(err)
+	}
+	if sent.Content != "@everyone <@&300> hi <@2>" {
+		t.Errorf("content changed to %q", sent.Content)
+	}
+	if parse := sent.AllowedMentions.Parse; len(parse) != 1 || parse[0] != "users" {
+		t.Errorf("got allowed mentions %q, want only users", parse)
+	}
+}
+
+func TestSendRestrictedEscape(t *testing.T) {
+	session := &sendSession{}
+
+	if _, err := SendRestricted(session, 200, "@everyone @here <@&300> <@2>"); err != nil {
+		t.Fatal(err)
+	}
+	want := "@\u200beveryone @\u200bhere <@\u200b&300> <@2>"
+	if len(session.contents) != 1 || session.contents[0] != want {
+		t.Errorf("got %q, want %q", session.contents, want)
+	}
+}
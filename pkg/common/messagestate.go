@@ -1,22 +1,81 @@
 package common
 
 import (
+	"context"
+	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/andersfylling/disgord"
 )
 
+// BotID is the bot's own user ID, mentions of it are accepted as command prefix.
+var BotID disgord.Snowflake
+
 // MessageState represents a wrapper around Disgord's MessageCreate with helper functions.
 type MessageState struct {
 	Session disgord.Session
 	Event   *disgord.MessageCreate
+	// Args are the command arguments parsed according to the command's usage.
+	Args Arguments
+	// Ctx is cancelled when the command times out or the bot shuts down.
+	Ctx context.Context
+	// Config is the configuration of the bot.
+	Config *Config
+	// Outcome records whether the command failed, it is shared by all copies of the state.
+	Outcome *Outcome
+}
+
+// Outcome represents the result of a command execution.
+type Outcome struct {
+	mu  sync.Mutex
+	err error
+}
+
+// Err returns the error the command failed with or nil.
+func (o *Outcome) Err() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.err
+}
+
+// Context returns the context of the command execution, which is never nil.
+func (s MessageState) Context() context.Context {
+	if s.Ctx == nil {
+		return context.Background()
+	}
+	return s.Ctx
 }
 
 // Send sends a message to the channel.
+// Nothing is sent once the context is done, since the user was already told about it.
 func (s MessageState) Send(data ...interface{}) (*disgord.Message, error) {
+	if err := s.Context().Err(); err != nil {
+		return nil, err
+	}
 	return s.Session.SendMsg(s.Event.Message.ChannelID, data...)
 }
 
+// SendRestricted sends user provided content to the channel, mentions of @everyone, @here and roles don't notify anyone.
+func (s MessageState) SendRestricted(content string) (*disgord.Message, error) {
+	if err := s.Context().Err(); err != nil {
+		return nil, err
+	}
+	return SendRestricted(s.Session, s.Event.Message.ChannelID, content)
+}
+
+// Fail logs an unexpected error, tells the user about it and marks the command as failed.
+func (s MessageState) Fail(err error) {
+	if s.Outcome != nil {
+		s.Outcome.mu.Lock()
+		s.Outcome.err = err
+		s.Outcome.mu.Unlock()
+	}
+
+	s.Session.Logger().Error(err)
+	s.Reply(fmt.Sprintf("Unexpected error: %s", err.Error()))
+}
+
 // Reply sends a message to the channel and mentions the user.
 func (s MessageState) Reply(content string) (*disgord.Message, error) {
 	// Don't mention the user in a DM.
@@ -29,6 +88,10 @@ func (s MessageState) Reply(content string) (*disgord.Message, error) {
 
 // DM sends a direct message to the user.
 func (s MessageState) DM(data ...interface{}) (*disgord.Message, error) {
+	if err := s.Context().Err(); err != nil {
+		return nil, err
+	}
+
 	ch, err := s.Session.CreateDM(s.Event.Message.Author.ID)
 	if err != nil {
 		s.Session.Logger().Error(err)
@@ -52,13 +115,69 @@ func (s MessageState) DMEmbed(embed *disgord.Embed) (*disgord.Message, error) {
 	})
 }
 
-// HasPrefix returns a bool which indicates whether the message content starts with the prefix.
+// SendPaginated sends rich embedded content to the channel, split across pages the user can turn with reactions.
+func (s MessageState) SendPaginated(embed *disgord.Embed) (*disgord.Message, error) {
+	if err := s.Context().Err(); err != nil {
+		return nil, err
+	}
+	return SendPaginated(s.Session, s.Event.Message.ChannelID, s.UserID(), embed)
+}
+
+// DMPaginated sends rich embedded content as a direct message to the user, split across pages.
+func (s MessageState) DMPaginated(embed *disgord.Embed) (*disgord.Message, error) {
+	if err := s.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	ch, err := s.Session.CreateDM(s.Event.Message.Author.ID)
+	if err != nil {
+		s.Session.Logger().Error(err)
+		return nil, err
+	}
+
+	return SendPaginated(s.Session, ch.ID, s.UserID(), embed)
+}
+
+// HasPrefix returns a bool which indicates whether the message content starts with the prefix or a mention of the bot.
 func (s MessageState) HasPrefix() bool {
-	return strings.HasPrefix(s.Event.Message.Content, CommandPrefix)
+	return s.Prefix() != ""
+}
+
+// Prefix returns the prefix the message content starts with, which is either the guild's command prefix,
+// a mention of the bot (including the nickname mention) or an empty string.
+func (s MessageState) Prefix() string {
+	content := s.Event.Message.Content
+	if !BotID.Empty() {
+		for _, mention := range []string{fmt.Sprintf("<@%s>", BotID), fmt.Sprintf("<@!%s>", BotID)} {
+			if strings.HasPrefix(content, mention) {
+				return mention
+			}
+		}
+	}
+	if prefix := s.GuildPrefix(); prefix != "" && strings.HasPrefix(content, prefix) {
+		return prefix
+	}
+	return ""
+}
+
+// GuildPrefix returns the effective command prefix in the message's guild.
+func (s MessageState) GuildPrefix() string {
+	return GuildPrefix(s.GuildID(), s.Config.CommandPrefix)
+}
+
+// IsMentionPrefix returns a bool which indicates whether the message is addressed to the bot by mentioning it.
+func (s MessageState) IsMentionPrefix() bool {
+	prefix := s.Prefix()
+	return prefix != "" && prefix != s.GuildPrefix()
 }
 
 // IsDMChannel returns a bool which indicates whether the message's channel is a DM channel.
 func (s MessageState) IsDMChannel() bool {
+	// Guild messages are never sent in a DM channel, which saves the channel lookup.
+	if !s.GuildID().Empty() {
+		return false
+	}
+
 	ch, err := s.Session.GetChannel(s.Event.Message.ChannelID)
 	if err != nil {
 		s.Session.Logger().Error(err)
@@ -83,22 +202,53 @@ func (s MessageState) UserID() disgord.Snowflake {
 	return s.Event.Message.Author.ID
 }
 
-// UserPermission returns the message author's permission level.
+// GuildID returns the ID of the guild the message was sent in (empty in a DM).
+func (s MessageState) GuildID() disgord.Snowflake {
+	return s.Event.Message.GuildID
+}
+
+// IsGuildOwner returns a bool which indicates whether the message author owns the guild.
+func (s MessageState) IsGuildOwner() bool {
+	if s.GuildID().Empty() {
+		return false
+	}
+
+	guild, err := s.Session.GetGuild(s.GuildID())
+	if err != nil {
+		s.Session.Logger().Error(err)
+		return false
+	}
+
+	return guild.OwnerID == s.UserID()
+}
+
+// UserPermission returns the message author's permission level, which depends on their roles in a guild.
 func (s MessageState) UserPermission() PermissionLevel {
-	if s.UserID() == DeveloperID {
+	if s.Config.IsOwner(s.UserID()) {
 		return PermissionDeveloper
 	}
-	return PermissionDefault
+	if s.GuildID().Empty() {
+		return PermissionDefault
+	}
+	return MemberPermission(s.Session, s.GuildID(), s.UserID())
 }
 
 // UserCommand returns the command string from the message's content.
 func (s MessageState) UserCommand() string {
-	return strings.Replace(s.MessageParts()[0], CommandPrefix, "", 1)
+	return s.commandParts()[0]
 }
 
 // UserCommandArgs returns the arguments of the command string from the message's content.
 func (s MessageState) UserCommandArgs() []string {
-	return s.MessageParts()[1:]
+	return s.commandParts()[1:]
+}
+
+// commandParts returns the message's content without prefix split by whitespace.
+func (s MessageState) commandParts() []string {
+	content := strings.TrimPrefix(s.Event.Message.Content, s.Prefix())
+	// A mention is usually followed by a space.
+	content = strings.TrimLeft(content, " ")
+	return strings.Split(content, " ")
 }
 
 // IsBot returns a bool which indicates whether the user is a bot.
@@ -0,0 +1,70 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andersfylling/disgord"
+)
+
+// newDMState returns the state of a direct message, which uses the default prefix without a database.
+func newDMState(content string) MessageState {
+	return MessageState{
+		Event: &disgord.MessageCreate{
+			Message: &disgord.Message{
+				Content: content,
+				Author:  &disgord.User{ID: 2},
+			},
+		},
+		Config: &Config{CommandPrefix: "+"},
+	}
+}
+
+func TestMessageStatePrefix(t *testing.T) {
+	defer func(id disgord.Snowflake) { BotID = id }(BotID)
+	BotID = 42
+
+	tests := []struct {
+		content string
+		prefix  string
+		mention bool
+		command string
+		args    []string
+	}{
+		{"+remind tomorrow 9am", "+", false, "remind", []string{"tomorrow", "9am"}},
+		{"<@42> remind tomorrow", "<@42>", true, "remind", []string{"tomorrow"}},
+		{"<@!42> remind tomorrow", "<@!42>", true, "remind", []string{"tomorrow"}},
+		{"<@42>remind", "<@42>", true, "remind", []string{}},
+		{"<@42>", "<@42>", true, "", []string{}},
+		{"<@43> remind", "", false, "<@43>", []string{"remind"}},
+		{"remind", "", false, "remind", []string{}},
+	}
+
+	for _, test := range tests {
+		s := newDMState(test.content)
+		if prefix := s.Prefix(); prefix != test.prefix {
+			t.Errorf("Prefix(%q) = %q, want %q", test.content, prefix, test.prefix)
+		}
+		if has := s.HasPrefix(); has != (test.prefix != "") {
+			t.Errorf("HasPrefix(%q) = %v", test.content, has)
+		}
+		if mention := s.IsMentionPrefix(); mention != test.mention {
+			t.Errorf("IsMentionPrefix(%q) = %v, want %v", test.content, mention, test.mention)
+		}
+		if command := s.UserCommand(); command != test.command {
+			t.Errorf("UserCommand(%q) = %q, want %q", test.content, command, test.command)
+		}
+		if args := s.UserCommandArgs(); !reflect.DeepEqual(args, test.args) {
+			t.Errorf("UserCommandArgs(%q) = %q, want %q", test.content, args, test.args)
+		}
+	}
+}
+
+func TestMessageStatePrefixWithoutBotID(t *testing.T) {
+	defer func(id disgord.Snowflake) { BotID = id }(BotID)
+	BotID = 0
+
+	if s := newDMState("<@0> remind"); s.HasPrefix() {
+		t.Error("mentions must not be a prefix before the bot's ID is known")
+	}
+}
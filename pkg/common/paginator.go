@@ -0,0 +1,192 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andersfylling/disgord"
+)
+
+const (
+	// PageTimeout is the time after which a paginated message stops reacting to page changes.
+	PageTimeout = 2 * time.Minute
+
+	// Discord allows 25 fields and 6000 characters per embed, pages stay well below that.
+	maxPageFields     = 10
+	maxPageCharacters = 5500
+	maxFieldName      = 256
+	maxFieldValue     = 1024
+
+	emojiPrevious = "\u2b05\ufe0f"
+	emojiNext     = "\u27a1\ufe0f"
+)
+
+// paginator represents a sent message whose embed fields are split across pages.
+type paginator struct {
+	session   disgord.Session
+	channelID disgord.Snowflake
+	messageID disgord.Snowflake
+	// userID is the only user allowed to turn the pages.
+	userID disgord.Snowflake
+	pages  []*disgord.Embed
+	page   int
+}
+
+var (
+	paginators   = map[disgord.Snowflake]*paginator{}
+	paginatorsMu sync.Mutex
+)
+
+// SendPaginated sends an embed to a channel, splitting its fields across pages if they don't fit into one.
+// The user can turn the pages with reactions until PageTimeout expires.
+func SendPaginated(session disgord.Session, channelID, userID disgord.Snowflake, embed *disgord.Embed) (*disgord.Message, error) {
+	pages := Paginate(embed)
+
+	msg, err := session.SendMsg(channelID, &disgord.CreateMessageParams{
+		Embed: pages[0],
+	})
+	if err != nil || len(pages) == 1 {
+		return msg, err
+	}
+
+	// Messages which can't be edited (e.g. interaction responses) receive all pages at once.
+	if msg.ID.Empty() {
+		for _, page := range pages[1:] {
+			if _, err := session.SendMsg(channelID, &disgord.CreateMessageParams{Embed: page}); err != nil {
+				return msg, err
+			}
+		}
+		return msg, nil
+	}
+
+	p := &paginator{
+		session:   session,
+		channelID: channelID,
+		messageID: msg.ID,
+		userID:    userID,
+		pages:     pages,
+	}
+
+	paginatorsMu.Lock()
+	paginators[msg.ID] = p
+	paginatorsMu.Unlock()
+
+	for _, emoji := range []string{emojiPrevious, emojiNext} {
+		if err := session.CreateReaction(channelID, msg.ID, emoji); err != nil {
+			session.Logger().Error(err)
+		}
+	}
+
+	time.AfterFunc(PageTimeout, p.expire)
+
+	return msg, nil
+}
+
+// Paginate splits the fields of an embed into pages, each page is a copy of the embed with a page footer.
+// An embed without too many fields becomes a single page without page footer.
+func Paginate(embed *disgord.Embed) []*disgord.Embed {
+	base := len(embed.Title) + len(embed.Description)
+
+	var chunks [][]*disgord.EmbedField
+	var chunk []*disgord.EmbedField
+	size := base
+	for _, field := range embed.Fields {
+		field = &disgord.EmbedField{
+			Name:   Truncate(field.Name, maxFieldName),
+			Value:  Truncate(field.Value, maxFieldValue),
+			Inline: field.Inline,
+		}
+		length := len(field.Name) + len(field.Value)
+
+		if len(chunk) > 0 && (len(chunk) >= maxPageFields || size+length > maxPageCharacters) {
+			chunks = append(chunks, chunk)
+			chunk = nil
+			size = base
+		}
+		chunk = append(chunk, field)
+		size += length
+	}
+	chunks = append(chunks, chunk)
+
+	pages := make([]*disgord.Embed, len(chunks))
+	for i, fields := range chunks {
+		page := embed.DeepCopy().(*disgord.Embed)
+		page.Fields = fields
+		if len(chunks) > 1 {
+			page.Footer = &disgord.EmbedFooter{
+				Text: fmt.Sprintf("Page %d/%d", i+1, len(chunks)),
+			}
+		}
+		pages[i] = page
+	}
+	return pages
+}
+
+// HandlePageReaction turns the page of a paginated message if its user added or removed a page reaction.
+// Both are handled since removing the user's reaction requires permissions the bot may not have.
+func HandlePageReaction(session disgord.Session, channelID, messageID, userID disgord.Snowflake, emoji *disgord.Emoji) {
+	if emoji == nil {
+		return
+	}
+
+	paginatorsMu.Lock()
+	p, ok := paginators[messageID]
+	paginatorsMu.Unlock()
+	if !ok || p.userID != userID {
+		return
+	}
+
+	var page int
+	switch strings.TrimSuffix(emoji.Name, "\ufe0f") {
+	case strings.TrimSuffix(emojiPrevious, "\ufe0f"):
+		page = p.turn(-1)
+	case strings.TrimSuffix(emojiNext, "\ufe0f"):
+		page = p.turn(1)
+	default:
+		return
+	}
+
+	_, err := session.UpdateMessage(channelID, messageID).SetEmbed(p.pages[page]).Execute()
+	if err != nil {
+		session.Logger().Error(err)
+	}
+}
+
+// turn moves by delta pages, wrapping around at both ends, and returns the new page.
+func (p *paginator) turn(delta int) int {
+	paginatorsMu.Lock()
+	defer paginatorsMu.Unlock()
+
+	p.page = (p.page + delta + len(p.pages)) % len(p.pages)
+	return p.page
+}
+
+// expire stops handling page reactions and removes the bot's reactions.
+func (p *paginator) expire() {
+	paginatorsMu.Lock()
+	delete(paginators, p.messageID)
+	paginatorsMu.Unlock()
+
+	for _, emoji := range []string{emojiPrevious, emojiNext} {
+		if err := p.session.DeleteOwnReaction(p.channelID, p.messageID, emoji); err != nil {
+			p.session.Logger().Error(err)
+		}
+	}
+}
+
+// Truncate shortens s to at most max bytes without splitting a character.
+func Truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+
+	const ellipsis = "…"
+	end := max - len(ellipsis)
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end] + ellipsis
+}
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/andersfylling/disgord"
+)
+
+func TestPaginateSinglePage(t *testing.T) {
+	long := strings.Repeat("a", 2000)
+	embed := &disgord.Embed{
+		Title:  "Reminders",
+		Footer: &disgord.EmbedFooter{Text: "footer"},
+		Fields: []*disgord.EmbedField{
+			{Name: strings.Repeat("n", 300), Value: long},
+			{Name: "short", Value: "value", Inline: true},
+		},
+	}
+
+	pages := Paginate(embed)
+	if len(pages) != 1 {
+		t.Fatalf("got %d pages, want 1", len(pages))
+	}
+
+	page := pages[0]
+	if n := len(page.Fields[0].Name); n > maxFieldName {
+		t.Errorf("field name has %d bytes, want at most %d", n, maxFieldName)
+	}
+	if n := len(page.Fields[0].Value); n > maxFieldValue {
+		t.Errorf("field value has %d bytes, want at most %d", n, maxFieldValue)
+	}
+	if f := page.Fields[1]; f.Name != "short" || f.Value != "value" || !f.Inline {
+		t.Errorf("short field changed to %+v", f)
+	}
+	if page.Footer == nil || page.Footer.Text != "footer" {
+		t.Errorf("a single page must keep the footer, got %+v", page.Footer)
+	}
+	if embed.Fields[0].Value != long {
+		t.Error("the original embed must not be changed")
+	}
+}
+
+func TestPaginateSplit(t *testing.T) {
+	embed := &disgord.Embed{Title: "Stats"}
+	for i := 0; i < 25; i++ {
+		embed.Fields = append(embed.Fields, &disgord.EmbedField{Name: fmt.Sprintf("field %d", i), Value: "value"})
+	}
+
+	pages := Paginate(embed)
+	if len(pages) != 3 {
+		t.Fatalf("got %d pages, want 3", len(pages))
+	}
+	for i, want := range []int{10, 10, 5} {
+		if n := len(pages[i].Fields); n != want {
+			t.Errorf("page %d has %d fields, want %d", i+1, n, want)
+		}
+		if text := fmt.Sprintf("Page %d/3", i+1); pages[i].Footer == nil || pages[i].Footer.Text != text {
+			t.Errorf("page %d footer = %+v, want %q", i+1, pages[i].Footer, text)
+		}
+		if pages[i].Title != "Stats" {
+			t.Errorf("page %d title = %q", i+1, pages[i].Title)
+		}
+	}
+	if pages[2].Fields[0].Name != "field 20" {
+		t.Errorf("fields out of order, page 3 starts with %q", pages[2].Fields[0].Name)
+	}
+}
+
+func TestPaginateCharacters(t *testing.T) {
+	embed := &disgord.Embed{}
+	for i := 0; i < 8; i++ {
+		embed.Fields = append(embed.Fields, &disgord.EmbedField{Name: "name", Value: strings.Repeat("v", maxFieldValue)})
+	}
+
+	pages := Paginate(embed)
+	if len(pages) != 2 {
+		t.Fatalf("got %d pages, want 2", len(pages))
+	}
+	for i, page := range pages {
+		size := 0
+		for _, field := range page.Fields {
+			size += len(field.Name) + len(field.Value)
+		}
+		if size > maxPageCharacters {
+			t.Errorf("page %d has %d characters, want at most %d", i+1, size, maxPageCharacters)
+		}
+	}
+}
+
+func TestPaginateWithoutFields(t *testing.T) {
+	pages := Paginate(&disgord.Embed{Title: "Empty"})
+	if len(pages) != 1 || pages[0].Title != "Empty" || pages[0].Footer != nil {
+		t.Errorf("got %+v, want the embed as only page", pages)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if s := Truncate("short", 10); s != "short" {
+		t.Errorf("got %q", s)
+	}
+
+	s := Truncate(strings.Repeat("ä", 10), 9)
+	if len(s) > 9 || !utf8.ValidString(s) || !strings.HasSuffix(s, "…") {
+		t.Errorf("got %q, want valid UTF-8 of at most 9 bytes ending with an ellipsis", s)
+	}
+}
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/andersfylling/disgord"
+)
+
+const (
+	// maxReportLength is the maximum length of a Discord message.
+	maxReportLength = 2000
+	// maxCauseLength is the maximum length of the origin and cause in a report, which leaves room for the stack trace.
+	maxCauseLength = 500
+)
+
+// ReportPanic logs a recovered panic with its stack trace and sends a report to the owners.
+// The returned reference ID identifies the report and can be shown to the user.
+// It has to be called by the deferred function which recovered, otherwise the stack trace is useless.
+func ReportPanic(session disgord.Session, owners []disgord.Snowflake, cause interface{}, origin string) string {
+	ref := newReference()
+	stack := string(debug.Stack())
+
+	Logger.Error(fmt.Sprintf("Panic (ref %s) in %s: %v\n%s", ref, origin, cause, stack))
+
+	report := fmt.Sprintf(
+		"**Panic (ref %s)**\nIn: %s\nCause: %s\n",
+		ref,
+		Truncate(origin, maxCauseLength),
+		Truncate(fmt.Sprint(cause), maxCauseLength),
+	)
+	// Truncate the stack trace to fit into a single message.
+	if room := maxReportLength - len(report) - len("```\n```"); len(stack) > room {
+		stack = stack[:room]
+	}
+	report += "```\n" + strings.TrimSpace(stack) + "```"
+
+	NotifyOwners(session, owners, report)
+
+	return ref
+}
+
+// NotifyOwners sends a direct message to all owners of the bot.
+func NotifyOwners(session disgord.Session, owners []disgord.Snowflake, message string) {
+	for _, id := range owners {
+		ch, err := session.CreateDM(id)
+		if err != nil {
+			Logger.Error(err)
+			continue
+		}
+		_, err = session.SendMsg(ch.ID, message)
+		if err != nil {
+			Logger.Error(err)
+		}
+	}
+}
+
+// newReference returns a short random reference ID, e.g. "3FA9C1".
+func newReference() string {
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		return "000000"
+	}
+	return strings.ToUpper(hex.EncodeToString(b))
+}
@@ -1,10 +1,43 @@
 package common
 
+import (
+	"fmt"
+	"strings"
+)
+
 // PermissionLevel represents the permission level for a command.
 type PermissionLevel uint
 
-// Command permission level.
+// Command permission level, each level includes the ones before.
 const (
 	PermissionDefault PermissionLevel = iota
+	// PermissionModerator members managing messages or members, or having a moderator role.
+	PermissionModerator
+	// PermissionAdmin the guild owner and members managing the guild, or having an admin role.
+	PermissionAdmin
 	PermissionDeveloper
 )
+
+// permissionLevelNames maps the permission levels to their names.
+var permissionLevelNames = map[PermissionLevel]string{
+	PermissionDefault:   "default",
+	PermissionModerator: "moderator",
+	PermissionAdmin:     "admin",
+	PermissionDeveloper: "developer",
+}
+
+// String returns the name of the permission level.
+func (l PermissionLevel) String() string {
+	return permissionLevelNames[l]
+}
+
+// ParsePermissionLevel returns the permission level by name.
+func ParsePermissionLevel(name string) (PermissionLevel, error) {
+	name = strings.ToLower(name)
+	for level, levelName := range permissionLevelNames {
+		if levelName == name {
+			return level, nil
+		}
+	}
+	return PermissionDefault, fmt.Errorf("unknown permission level \"%s\"", name)
+}
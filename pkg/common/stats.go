@@ -0,0 +1,106 @@
+package common
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/andersfylling/disgord"
+	"github.com/jinzhu/gorm"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+// CommandUse represents a single command invocation to be recorded.
+type CommandUse struct {
+	Command  string
+	GuildID  disgord.Snowflake
+	UserID   disgord.Snowflake
+	DM       bool
+	Failed   bool
+	Rejected bool
+	Duration time.Duration
+}
+
+// statsMu serializes the read-modify-write of the aggregated rows.
+var statsMu sync.Mutex
+
+// StatsDay returns the unix timestamp of the start of t's day (UTC).
+func StatsDay(t time.Time) int64 {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix()
+}
+
+// RecordCommand adds an invocation to the command statistics of today.
+func RecordCommand(use CommandUse) error {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	stat := models.CommandStat{
+		Day:     StatsDay(time.Now()),
+		Command: use.Command,
+		GuildID: use.GuildID,
+		UserID:  use.UserID,
+		DM:      use.DM,
+	}
+	err := DB.Where(map[string]interface{}{
+		"day":      stat.Day,
+		"command":  stat.Command,
+		"guild_id": stat.GuildID,
+		"user_id":  stat.UserID,
+		"dm":       stat.DM,
+	}).FirstOrCreate(&stat).Error
+	if err != nil {
+		return err
+	}
+
+	return DB.Model(&stat).UpdateColumns(map[string]interface{}{
+		"uses":       gorm.Expr("uses + 1"),
+		"errors":     gorm.Expr("errors + ?", boolToInt(use.Failed)),
+		"rejections": gorm.Expr("rejections + ?", boolToInt(use.Rejected)),
+		"duration":   gorm.Expr("duration + ?", use.Duration.Nanoseconds()/int64(time.Millisecond)),
+	}).Error
+}
+
+// RecordReminders adds created and delivered reminders to the reminder statistics of today.
+func RecordReminders(created, delivered int64) error {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	stat := models.ReminderStat{Day: StatsDay(time.Now())}
+	err := DB.Where(models.ReminderStat{Day: stat.Day}).FirstOrCreate(&stat).Error
+	if err != nil {
+		return err
+	}
+
+	return DB.Model(&stat).UpdateColumns(map[string]interface{}{
+		"created":   gorm.Expr("created + ?", created),
+		"delivered": gorm.Expr("delivered + ?", delivered),
+	}).Error
+}
+
+// GetCommandStats returns all command statistics since the day of since, the query is cancelled once ctx is done.
+func GetCommandStats(ctx context.Context, since time.Time) ([]models.CommandStat, error) {
+	var stats []models.CommandStat
+	err := Transaction(ctx, func(tx *gorm.DB) error {
+		return tx.Where("day >= ?", StatsDay(since)).Find(&stats).Error
+	})
+	return stats, err
+}
+
+// GetReminderStats returns the reminder statistics since the day of since ordered by day,
+// the query is cancelled once ctx is done.
+func GetReminderStats(ctx context.Context, since time.Time) ([]models.ReminderStat, error) {
+	var stats []models.ReminderStat
+	err := Transaction(ctx, func(tx *gorm.DB) error {
+		return tx.Where("day >= ?", StatsDay(since)).Order("day").Find(&stats).Error
+	})
+	return stats, err
+}
+
+// boolToInt returns 1 for true and 0 for false.
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
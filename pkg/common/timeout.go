@@ -0,0 +1,34 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParseTimeouts parses command timeout overrides mapped by command name.
+// Entries are separated by semicolons and have the format "command:duration", e.g. "stats:1m;remind:10s".
+func ParseTimeouts(str string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+
+	for _, entry := range strings.Split(str, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid timeout \"%s\"", entry)
+		}
+
+		d, err := time.ParseDuration(parts[1])
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid timeout duration \"%s\"", parts[1])
+		}
+
+		timeouts[strings.ToLower(parts[0])] = d
+	}
+
+	return timeouts, nil
+}
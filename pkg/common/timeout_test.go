@@ -0,0 +1,26 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeouts(t *testing.T) {
+	timeouts, err := ParseTimeouts(" Stats:1m; remind:10s ;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(timeouts) != 2 || timeouts["stats"] != time.Minute || timeouts["remind"] != 10*time.Second {
+		t.Errorf("got %v", timeouts)
+	}
+
+	for _, str := range []string{"stats", "stats:1m:2m", "stats:soon", "stats:0s", "stats:-1s"} {
+		if _, err := ParseTimeouts(str); err == nil {
+			t.Errorf("ParseTimeouts(%q) must fail", str)
+		}
+	}
+
+	if timeouts, err := ParseTimeouts(""); err != nil || len(timeouts) != 0 {
+		t.Errorf("got %v and %v for an empty string", timeouts, err)
+	}
+}
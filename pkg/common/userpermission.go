@@ -0,0 +1,153 @@
+package common
+
+import (
+	"sync"
+	"time"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+// permissionTTL is the time a member's resolved permission level is cached.
+const permissionTTL = time.Minute
+
+// memberKey identifies a member of a guild.
+type memberKey struct {
+	guildID disgord.Snowflake
+	userID  disgord.Snowflake
+}
+
+// cachedPermission represents a resolved permission level until it expires.
+type cachedPermission struct {
+	level   PermissionLevel
+	expires time.Time
+}
+
+var (
+	memberPermissions   = map[memberKey]cachedPermission{}
+	memberPermissionsMu sync.Mutex
+)
+
+// GetRoleLevels returns the permission levels granted by the roles of a guild.
+func GetRoleLevels(guildID disgord.Snowflake) ([]models.GuildRole, error) {
+	var roles []models.GuildRole
+	err := DB.Where(models.GuildRole{
+		GuildID: guildID,
+	}).Find(&roles).Error
+	return roles, err
+}
+
+// SetRoleLevel maps a role of a guild to a permission level, PermissionDefault removes the mapping.
+func SetRoleLevel(guildID, roleID disgord.Snowflake, level PermissionLevel) error {
+	defer ForgetGuildPermissions(guildID)
+
+	where := models.GuildRole{GuildID: guildID, RoleID: roleID}
+	if level == PermissionDefault {
+		return DB.Unscoped().Where(where).Delete(&models.GuildRole{}).Error
+	}
+
+	role := &models.GuildRole{}
+	err := DB.Where(where).FirstOrInit(role).Error
+	if err != nil {
+		return err
+	}
+	role.Level = uint(level)
+	return DB.Save(role).Error
+}
+
+// ForgetGuildPermissions removes the cached permission levels of a guild's members.
+func ForgetGuildPermissions(guildID disgord.Snowflake) {
+	memberPermissionsMu.Lock()
+	defer memberPermissionsMu.Unlock()
+
+	for key := range memberPermissions {
+		if key.guildID == guildID {
+			delete(memberPermissions, key)
+		}
+	}
+}
+
+// MemberPermission returns the permission level of a guild member, which is cached for a while.
+func MemberPermission(session disgord.Session, guildID, userID disgord.Snowflake) PermissionLevel {
+	key := memberKey{guildID: guildID, userID: userID}
+
+	memberPermissionsMu.Lock()
+	cached, ok := memberPermissions[key]
+	memberPermissionsMu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.level
+	}
+
+	level, err := resolveMemberPermission(session, guildID, userID)
+	if err != nil {
+		// Errors are not cached, the next command tries again.
+		session.Logger().Error(err)
+		return PermissionDefault
+	}
+
+	memberPermissionsMu.Lock()
+	memberPermissions[key] = cachedPermission{level: level, expires: time.Now().Add(permissionTTL)}
+	memberPermissionsMu.Unlock()
+
+	return level
+}
+
+// resolveMemberPermission determines the permission level of a guild member from ownership,
+// the Discord permissions of their roles and the levels the guild mapped to their roles.
+func resolveMemberPermission(session disgord.Session, guildID, userID disgord.Snowflake) (PermissionLevel, error) {
+	guild, err := session.GetGuild(guildID)
+	if err != nil {
+		return PermissionDefault, err
+	}
+	if guild.OwnerID == userID {
+		return PermissionAdmin, nil
+	}
+
+	member, err := session.GetMember(guildID, userID)
+	if err != nil {
+		return PermissionDefault, err
+	}
+
+	roles, err := session.GetGuildRoles(guildID)
+	if err != nil {
+		return PermissionDefault, err
+	}
+
+	mapped, err := GetRoleLevels(guildID)
+	if err != nil {
+		return PermissionDefault, err
+	}
+
+	// The @everyone role has the ID of the guild and applies to every member.
+	memberRoles := map[disgord.Snowflake]bool{guildID: true}
+	for _, id := range member.Roles {
+		memberRoles[id] = true
+	}
+
+	var permissions disgord.PermissionBits
+	for _, role := range roles {
+		if memberRoles[role.ID] {
+			permissions |= role.Permissions
+		}
+	}
+
+	level := PermissionDefault
+	switch {
+	case permissions&(disgord.PermissionAdministrator|disgord.PermissionManageServer) != 0:
+		level = PermissionAdmin
+	case permissions&(disgord.PermissionManageMessages|disgord.PermissionKickMembers|disgord.PermissionBanMembers) != 0:
+		level = PermissionModerator
+	}
+
+	for _, role := range mapped {
+		if memberRoles[role.RoleID] && PermissionLevel(role.Level) > level {
+			level = PermissionLevel(role.Level)
+		}
+	}
+
+	// Developer can only be granted by configuration.
+	if level > PermissionAdmin {
+		level = PermissionAdmin
+	}
+	return level, nil
+}
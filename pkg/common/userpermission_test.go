@@ -0,0 +1,151 @@
+package common
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/andersfylling/disgord"
+	"github.com/andersfylling/disgord/logger"
+)
+
+const (
+	permGuild = disgord.Snowflake(300)
+	permOwner = disgord.Snowflake(1)
+)
+
+// guildSession serves a guild owned by permOwner with the given roles and member roles.
+type guildSession struct {
+	disgord.Session
+	roles       []*disgord.Role
+	memberRoles map[disgord.Snowflake][]disgord.Snowflake
+	// requests counts the GetGuild calls.
+	requests int
+	fail     bool
+}
+
+func (s *guildSession) GetGuild(id disgord.Snowflake, flags ...disgord.Flag) (*disgord.Guild, error) {
+	s.requests++
+	if s.fail {
+		return nil, errors.New("unavailable")
+	}
+	return &disgord.Guild{ID: id, OwnerID: permOwner}, nil
+}
+
+func (s *guildSession) GetMember(guildID, userID disgord.Snowflake, flags ...disgord.Flag) (*disgord.Member, error) {
+	return &disgord.Member{GuildID: guildID, Roles: s.memberRoles[userID]}, nil
+}
+
+func (s *guildSession) GetGuildRoles(guildID disgord.Snowflake, flags ...disgord.Flag) ([]*disgord.Role, error) {
+	return s.roles, nil
+}
+
+func (s *guildSession) Logger() logger.Logger {
+	return logger.Empty{}
+}
+
+func TestResolveMemberPermission(t *testing.T) {
+	const (
+		kickRole   = disgord.Snowflake(10)
+		manageRole = disgord.Snowflake(11)
+		mappedRole = disgord.Snowflake(12)
+		devRole    = disgord.Snowflake(13)
+	)
+	session := &guildSession{
+		roles: []*disgord.Role{
+			{ID: permGuild, Permissions: disgord.PermissionSendMessages},
+			{ID: kickRole, Permissions: disgord.PermissionKickMembers},
+			{ID: manageRole, Permissions: disgord.PermissionManageServer},
+			{ID: mappedRole},
+			{ID: devRole},
+		},
+		memberRoles: map[disgord.Snowflake][]disgord.Snowflake{
+			3: {kickRole},
+			4: {manageRole},
+			5: {mappedRole},
+			6: {kickRole, mappedRole},
+			7: {devRole},
+		},
+	}
+
+	if err := SetRoleLevel(permGuild, mappedRole, PermissionAdmin); err != nil {
+		t.Fatal(err)
+	}
+	defer SetRoleLevel(permGuild, mappedRole, PermissionDefault)
+	if err := SetRoleLevel(permGuild, devRole, PermissionDeveloper); err != nil {
+		t.Fatal(err)
+	}
+	defer SetRoleLevel(permGuild, devRole, PermissionDefault)
+
+	tests := []struct {
+		userID disgord.Snowflake
+		level  PermissionLevel
+	}{
+		{permOwner, PermissionAdmin},
+		{2, PermissionDefault},
+		{3, PermissionModerator},
+		{4, PermissionAdmin},
+		{5, PermissionAdmin},
+		{6, PermissionAdmin},
+		// Developer is only granted by configuration.
+		{7, PermissionAdmin},
+	}
+
+	for _, test := range tests {
+		level, err := resolveMemberPermission(session, permGuild, test.userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if level != test.level {
+			t.Errorf("user %d got %s, want %s", test.userID, level, test.level)
+		}
+	}
+
+	// The @everyone role applies to every member.
+	session.roles[0].Permissions = disgord.PermissionBanMembers
+	if level, _ := resolveMemberPermission(session, permGuild, 2); level != PermissionModerator {
+		t.Errorf("got %s, want the permissions of @everyone", level)
+	}
+}
+
+func TestMemberPermissionCache(t *testing.T) {
+	const guildID = permGuild + 1
+	session := &guildSession{}
+	defer ForgetGuildPermissions(guildID)
+
+	MemberPermission(session, guildID, 2)
+	MemberPermission(session, guildID, 2)
+	if session.requests != 1 {
+		t.Errorf("got %d requests, want the level to be cached", session.requests)
+	}
+
+	ForgetGuildPermissions(guildID)
+	MemberPermission(session, guildID, 2)
+	if session.requests != 2 {
+		t.Errorf("got %d requests, want the level to be resolved again", session.requests)
+	}
+
+	ForgetGuildPermissions(guildID)
+	session.fail = true
+	if level := MemberPermission(session, guildID, permOwner); level != PermissionDefault {
+		t.Errorf("got %s on error, want default", level)
+	}
+	session.fail = false
+	if level := MemberPermission(session, guildID, permOwner); level != PermissionAdmin {
+		t.Errorf("got %s, errors must not be cached", level)
+	}
+}
+
+func TestParsePermissionLevel(t *testing.T) {
+	for level, name := range permissionLevelNames {
+		parsed, err := ParsePermissionLevel(name)
+		if err != nil || parsed != level {
+			t.Errorf("ParsePermissionLevel(%q) = %s, %v", name, parsed, err)
+		}
+	}
+	if level, err := ParsePermissionLevel("Admin"); err != nil || level != PermissionAdmin {
+		t.Errorf("names must be case insensitive, got %s, %v", level, err)
+	}
+	if _, err := ParsePermissionLevel("root"); err == nil {
+		t.Error("unknown levels must be rejected")
+	}
+}
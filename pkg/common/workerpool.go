@@ -0,0 +1,143 @@
+package common
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+
+	"github.com/andersfylling/disgord"
+)
+
+// CommandQueue is the worker pool handling incoming commands.
+var CommandQueue *WorkerPool
+
+// WorkerPool represents a fixed number of workers processing jobs from bounded queues.
+// Jobs with the same key are always processed by the same worker, which preserves their order.
+type WorkerPool struct {
+	// Counters come first to be 64-bit aligned for atomic access.
+	processed int64
+	rejected  int64
+	maxDepth  int64
+
+	queues []chan func()
+	wg     sync.WaitGroup
+
+	// mu guards stopped, Submit holds it while sending so the queues are never closed in between.
+	mu      sync.RWMutex
+	stopped bool
+}
+
+// PoolMetrics represents a snapshot of the state of a worker pool.
+type PoolMetrics struct {
+	Workers  int
+	Capacity int
+	// Depth is the number of queued jobs, MaxDepth the highest depth seen so far.
+	Depth     int
+	MaxDepth  int
+	Processed int64
+	// Rejected counts the jobs which were not queued since the queue was full.
+	Rejected int64
+}
+
+// NewWorkerPool starts workers, each with a queue of size jobs.
+func NewWorkerPool(workers, size int) *WorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	if size < 1 {
+		size = 1
+	}
+
+	p := &WorkerPool{
+		queues: make([]chan func(), workers),
+	}
+	for i := range p.queues {
+		p.queues[i] = make(chan func(), size)
+		p.wg.Add(1)
+		go p.work(p.queues[i])
+	}
+	return p
+}
+
+// work processes the jobs of a queue until it is closed.
+func (p *WorkerPool) work(queue chan func()) {
+	defer p.wg.Done()
+	for job := range queue {
+		run(job)
+		atomic.AddInt64(&p.processed, 1)
+	}
+}
+
+// run runs a job and logs if it panics, which must not stop the worker or the bot.
+func run(job func()) {
+	defer func() {
+		if cause := recover(); cause != nil {
+			Logger.Error(fmt.Sprintf("Panic in queued job: %v\n%s", cause, debug.Stack()))
+		}
+	}()
+	job()
+}
+
+// Submit queues a job, jobs with the same key are processed in order of submission.
+// It returns false without blocking if the key's queue is full or the pool is stopped.
+func (p *WorkerPool) Submit(key disgord.Snowflake, job func()) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.stopped {
+		return false
+	}
+
+	queue := p.queues[uint64(key)%uint64(len(p.queues))]
+	select {
+	case queue <- job:
+	default:
+		atomic.AddInt64(&p.rejected, 1)
+		return false
+	}
+
+	depth := int64(p.depth())
+	for {
+		max := atomic.LoadInt64(&p.maxDepth)
+		if depth <= max || atomic.CompareAndSwapInt64(&p.maxDepth, max, depth) {
+			break
+		}
+	}
+	return true
+}
+
+// Stop stops accepting jobs and waits until all queued jobs are processed.
+// Jobs submitted afterwards are turned away, Stop may be called more than once.
+func (p *WorkerPool) Stop() {
+	p.mu.Lock()
+	if !p.stopped {
+		p.stopped = true
+		for _, queue := range p.queues {
+			close(queue)
+		}
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+// Metrics returns the current state of the worker pool.
+func (p *WorkerPool) Metrics() PoolMetrics {
+	return PoolMetrics{
+		Workers:   len(p.queues),
+		Capacity:  len(p.queues) * cap(p.queues[0]),
+		Depth:     p.depth(),
+		MaxDepth:  int(atomic.LoadInt64(&p.maxDepth)),
+		Processed: atomic.LoadInt64(&p.processed),
+		Rejected:  atomic.LoadInt64(&p.rejected),
+	}
+}
+
+// depth returns the number of queued jobs of all workers.
+func (p *WorkerPool) depth() int {
+	depth := 0
+	for _, queue := range p.queues {
+		depth += len(queue)
+	}
+	return depth
+}
@@ -0,0 +1,117 @@
+package common
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/andersfylling/disgord"
+)
+
+func TestWorkerPoolOrder(t *testing.T) {
+	p := NewWorkerPool(4, 100)
+
+	var mu sync.Mutex
+	got := map[disgord.Snowflake][]int{}
+	for i := 0; i < 50; i++ {
+		for key := disgord.Snowflake(1); key <= 3; key++ {
+			key, i := key, i
+			if !p.Submit(key, func() {
+				mu.Lock()
+				got[key] = append(got[key], i)
+				mu.Unlock()
+			}) {
+				t.Fatal("job rejected")
+			}
+		}
+	}
+	p.Stop()
+
+	for key, jobs := range got {
+		for i, job := range jobs {
+			if job != i {
+				t.Fatalf("jobs of key %d processed out of order: %v", key, jobs)
+			}
+		}
+	}
+	if metrics := p.Metrics(); metrics.Processed != 150 || metrics.Depth != 0 {
+		t.Errorf("got %+v", metrics)
+	}
+}
+
+func TestWorkerPoolFull(t *testing.T) {
+	p := NewWorkerPool(1, 1)
+	block := make(chan struct{})
+	started := make(chan struct{})
+
+	p.Submit(1, func() {
+		close(started)
+		<-block
+	})
+	<-started
+
+	if !p.Submit(1, func() {}) {
+		t.Error("the queue has room for one job")
+	}
+	if p.Submit(1, func() {}) {
+		t.Error("a full queue must turn jobs away")
+	}
+	close(block)
+	p.Stop()
+
+	if metrics := p.Metrics(); metrics.Rejected != 1 || metrics.MaxDepth != 1 || metrics.Capacity != 1 {
+		t.Errorf("got %+v", metrics)
+	}
+}
+
+func TestWorkerPoolStop(t *testing.T) {
+	p := NewWorkerPool(2, 10)
+
+	// Submitting while stopping must neither panic nor lose accepted jobs.
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	accepted, processed := 0, 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(key disgord.Snowflake) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				ok := p.Submit(key, func() {
+					mu.Lock()
+					processed++
+					mu.Unlock()
+				})
+				if ok {
+					mu.Lock()
+					accepted++
+					mu.Unlock()
+				}
+			}
+		}(disgord.Snowflake(i))
+	}
+	p.Stop()
+	wg.Wait()
+	p.Stop()
+
+	if p.Submit(1, func() {}) {
+		t.Error("a stopped pool must turn jobs away")
+	}
+	if accepted != processed {
+		t.Errorf("accepted %d jobs but processed %d", accepted, processed)
+	}
+}
+
+func TestWorkerPoolPanic(t *testing.T) {
+	p := NewWorkerPool(1, 10)
+
+	ran := false
+	p.Submit(1, func() { panic("broken job") })
+	p.Submit(1, func() { ran = true })
+	p.Stop()
+
+	if !ran {
+		t.Error("jobs after a panicking one must still run")
+	}
+	if metrics := p.Metrics(); metrics.Processed != 2 {
+		t.Errorf("got %+v", metrics)
+	}
+}
@@ -0,0 +1,527 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/commands"
+	"github.com/qysp/disgotify/pkg/common"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+// maxCommandRules is the maximum number of command rules per guild.
+const maxCommandRules = 100
+
+// newACLGroup returns the acl command group, which adds, lists, removes and tests the command rules of a guild.
+func newACLGroup() *commands.Group {
+	return commands.NewGroup(
+		"acl",
+		[]string{"rules"},
+		"Allow or deny commands to users, roles and channels of this server.",
+		common.CategorySettings,
+		&aclAdd{},
+		&aclList{},
+		&aclRemove{},
+		&aclTest{},
+	)
+}
+
+// parseRuleCommand parses the words naming a command, e.g. "reminder add", into its full name.
+// Unknown words are taken as name of a custom command, which is checked on execution.
+func parseRuleCommand(args []string) (interface{}, int, error) {
+	_, path, _ := Index.Resolve(args)
+	if path == nil {
+		return strings.ToLower(args[0]), 1, nil
+	}
+	return strings.Join(path, " "), len(path), nil
+}
+
+// ruleCommandExists returns a bool which indicates whether the command or custom command exists.
+func ruleCommandExists(s common.MessageState, name string) bool {
+	if Index.Get(strings.Fields(name)[0]) != nil {
+		return true
+	}
+	return findCustomCommand(s, name) != nil
+}
+
+// ruleCommands returns the names of the commands whose rules apply to an invocation, most specific first.
+// A subcommand is affected by its own rules, the ones of the command it wraps and the ones of its command group.
+func ruleCommands(inv *Invocation) []string {
+	var names []string
+	for _, name := range []string{inv.Name(), commands.Unwrap(inv.Command).Name(), inv.Path[0]} {
+		if !containsName(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// containsName returns a bool which indicates whether names contains name.
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateACL evaluates the command rules of the guild for a user in a channel.
+func evaluateACL(s common.MessageState, names []string, userID, channelID disgord.Snowflake) (common.RuleDecision, error) {
+	rules, err := common.GetCommandRules(s.GuildID())
+	if err != nil {
+		return common.RuleDecision{}, err
+	}
+
+	subject, err := ruleSubject(s, rules, names, userID, channelID)
+	if err != nil {
+		return common.RuleDecision{}, err
+	}
+	return common.EvaluateCommandRules(rules, names, subject), nil
+}
+
+// ruleSubject returns the subject of the command rules of the commands.
+func ruleSubject(s common.MessageState, rules []models.CommandRule, names []string, userID, channelID disgord.Snowflake) (common.RuleSubject, error) {
+	subject := common.RuleSubject{UserID: userID, ChannelID: channelID}
+	// The member is only requested if their roles matter.
+	if common.HasRoleRules(rules, names) {
+		member, err := s.Session.GetMember(s.GuildID(), userID)
+		if err != nil {
+			return subject, err
+		}
+		subject.Roles = member.Roles
+	}
+	return subject, nil
+}
+
+// describeDecision returns why a command rule decision was made.
+func describeDecision(decision common.RuleDecision) string {
+	if decision.Rule != nil {
+		return fmt.Sprintf(
+			"rule #%d: %s %s to use `%s`",
+			decision.Rule.ID,
+			decision.Rule.Effect(),
+			decision.Rule.Target(),
+			decision.Rule.Command,
+		)
+	}
+	if decision.Command != "" {
+		return fmt.Sprintf("`%s` is only allowed to others", decision.Command)
+	}
+	return "no rule applies"
+}
+
+// ACLMiddleware stops invocations which the guild's command rules deny to the user or in the channel.
+// Developers and the protected commands are exempt, so the rules cannot lock out the guild's admins.
+func ACLMiddleware(next Handler) Handler {
+	return func(inv *Invocation) {
+		s := inv.State
+		if s.GuildID().Empty() || isProtectedCommand(inv.Path[0]) || s.UserPermission() >= common.PermissionDeveloper {
+			next(inv)
+			return
+		}
+
+		decision, err := evaluateACL(s, ruleCommands(inv), s.UserID(), s.Event.Message.ChannelID)
+		if err != nil {
+			common.Logger.Error(err)
+			next(inv)
+			return
+		}
+
+		if !decision.Allowed {
+			if decision.Rule != nil && decision.Rule.TargetType == models.RuleTargetChannel {
+				s.Reply("This command cannot be used in this channel.")
+			} else {
+				s.Reply("You are not allowed to use this command.")
+			}
+			return
+		}
+		next(inv)
+	}
+}
+
+// aclAdd adds or replaces a command rule.
+type aclAdd struct{}
+
+func (*aclAdd) Name() string {
+	return "add"
+}
+
+func (*aclAdd) Aliases() []string {
+	return []string{"set"}
+}
+
+func (*aclAdd) Description() string {
+	return "Allow or deny a command to a user, role or channel."
+}
+
+func (*aclAdd) Category() common.CommandCategory {
+	return common.CategorySettings
+}
+
+func (*aclAdd) Permission() common.PermissionLevel {
+	return common.PermissionAdmin
+}
+
+func (*aclAdd) Active() bool {
+	return true
+}
+
+func (*aclAdd) Execute(s common.MessageState) {
+	if s.GuildID().Empty() {
+		s.Reply("Command rules can only be managed in a server.")
+		return
+	}
+
+	command := s.Args.String("command")
+	if isProtectedCommand(strings.Fields(command)[0]) {
+		s.Reply(fmt.Sprintf("The command \"%s\" cannot be configured.", command))
+		return
+	}
+	if !ruleCommandExists(s, command) {
+		s.Reply(fmt.Sprintf("Unknown command \"%s\".", command))
+		return
+	}
+
+	rule := s.Args.Value("target").(*models.CommandRule)
+	rule.GuildID = s.GuildID()
+	rule.Command = command
+	rule.Allow = s.Args.String("effect") == "allow"
+
+	rules, err := common.GetCommandRules(s.GuildID())
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+	// Replacing the rule of a command and target doesn't add one.
+	if len(rules) >= maxCommandRules && !hasRule(rules, rule) {
+		s.Reply(fmt.Sprintf("Sorry, there can't be more than %d command rules!", maxCommandRules))
+		return
+	}
+
+	err = common.SetCommandRule(rule)
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	reply := fmt.Sprintf("Rule #%d: %s %s to use `%s`.", rule.ID, rule.Effect(), rule.Target(), rule.Command)
+	if rule.Allow && rule.TargetType != models.RuleTargetChannel {
+		reply += " Users and roles without an allow rule can no longer use it."
+	} else if rule.Allow {
+		reply += " It can no longer be used in channels without an allow rule."
+	}
+	s.Reply(reply)
+}
+
+func (*aclAdd) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "effect",
+				Description: "Whether to allow or deny the command",
+				Choices:     []string{"allow", "deny"},
+			},
+			{
+				Name:        "command",
+				Description: "Name or alias of the command, command group or subcommand",
+				Parse:       parseRuleCommand,
+			},
+			{
+				Name:        "target",
+				Description: "Mention of the user, role or channel",
+				Parse:       common.ParseRuleTarget,
+			},
+		},
+		Notes: []common.UsageNote{
+			{
+				Name: "Precedence",
+				Value: "User and role rules as well as channel rules must both allow a command. " +
+					"Rules of a subcommand precede the ones of its group, user rules precede role rules and deny precedes allow. " +
+					"Once a command has allow rules, everyone else is denied.",
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Allowing a command only to a role", Args: "allow announce @Ops"},
+			{Description: "Denying a subcommand in a channel", Args: "deny reminder add #general"},
+			{Description: "Denying a command to a user", Args: "deny remind @someone"},
+		},
+	}
+}
+
+// hasRule returns a bool which indicates whether rules contain a rule for the command and target of rule.
+func hasRule(rules []models.CommandRule, rule *models.CommandRule) bool {
+	for _, r := range rules {
+		if r.Command == rule.Command && r.TargetType == rule.TargetType && r.TargetID == rule.TargetID {
+			return true
+		}
+	}
+	return false
+}
+
+// aclList lists the command rules.
+type aclList struct{}
+
+func (*aclList) Name() string {
+	return "list"
+}
+
+func (*aclList) Aliases() []string {
+	return []string{"ls"}
+}
+
+func (*aclList) Description() string {
+	return "List the command rules of this server."
+}
+
+func (*aclList) Category() common.CommandCategory {
+	return common.CategorySettings
+}
+
+func (*aclList) Permission() common.PermissionLevel {
+	return common.PermissionAdmin
+}
+
+func (*aclList) Active() bool {
+	return true
+}
+
+func (*aclList) Execute(s common.MessageState) {
+	if s.GuildID().Empty() {
+		s.Reply("Command rules can only be managed in a server.")
+		return
+	}
+
+	rules, err := common.GetCommandRules(s.GuildID())
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	// Rules are grouped by command in order of their first rule.
+	var names []string
+	lines := map[string][]string{}
+	for _, rule := range rules {
+		if s.Args.Has("command") && rule.Command != s.Args.String("command") {
+			continue
+		}
+		if _, ok := lines[rule.Command]; !ok {
+			names = append(names, rule.Command)
+		}
+		lines[rule.Command] = append(lines[rule.Command], fmt.Sprintf("#%d %s %s", rule.ID, rule.Effect(), rule.Target()))
+	}
+
+	if len(names) == 0 {
+		s.Reply("There are no command rules.")
+		return
+	}
+
+	var fields []*disgord.EmbedField
+	for _, name := range names {
+		fields = append(fields, &disgord.EmbedField{
+			Name:  name,
+			Value: strings.Join(lines[name], "\n"),
+		})
+	}
+
+	s.SendPaginated(&disgord.Embed{
+		Title:  "Command rules of this server:",
+		Color:  0xe5004c,
+		Fields: fields,
+	})
+}
+
+func (*aclList) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "command",
+				Description: "Only list the rules of this command",
+				Optional:    true,
+				Parse:       parseRuleCommand,
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Listing all command rules"},
+			{Description: "Listing the rules of a command", Args: "remind"},
+		},
+	}
+}
+
+// aclRemove removes a command rule.
+type aclRemove struct{}
+
+func (*aclRemove) Name() string {
+	return "remove"
+}
+
+func (*aclRemove) Aliases() []string {
+	return []string{"rm", "delete", "del"}
+}
+
+func (*aclRemove) Description() string {
+	return "Remove a command rule by its number."
+}
+
+func (*aclRemove) Category() common.CommandCategory {
+	return common.CategorySettings
+}
+
+func (*aclRemove) Permission() common.PermissionLevel {
+	return common.PermissionAdmin
+}
+
+func (*aclRemove) Active() bool {
+	return true
+}
+
+func (*aclRemove) Execute(s common.MessageState) {
+	if s.GuildID().Empty() {
+		s.Reply("Command rules can only be managed in a server.")
+		return
+	}
+
+	id := s.Args.Int("rule")
+	deleted, err := common.DeleteCommandRule(s.GuildID(), uint(id))
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+	if !deleted {
+		s.Reply(fmt.Sprintf("There is no rule #%d.", id))
+		return
+	}
+	s.Reply(fmt.Sprintf("Removed rule #%d.", id))
+}
+
+func (*aclRemove) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "rule",
+				Description: "Number of the rule as listed",
+				Type:        common.ArgumentInteger,
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Removing rule #3", Args: "3"},
+		},
+	}
+}
+
+// aclTest evaluates the command rules for a user in a channel.
+type aclTest struct{}
+
+func (*aclTest) Name() string {
+	return "test"
+}
+
+func (*aclTest) Aliases() []string {
+	return []string{"check"}
+}
+
+func (*aclTest) Description() string {
+	return "Check whether a user may use a command in a channel."
+}
+
+func (*aclTest) Category() common.CommandCategory {
+	return common.CategorySettings
+}
+
+func (*aclTest) Permission() common.PermissionLevel {
+	return common.PermissionAdmin
+}
+
+func (*aclTest) Active() bool {
+	return true
+}
+
+func (*aclTest) Execute(s common.MessageState) {
+	if s.GuildID().Empty() {
+		s.Reply("Command rules can only be managed in a server.")
+		return
+	}
+
+	command := s.Args.String("command")
+	if !ruleCommandExists(s, command) {
+		s.Reply(fmt.Sprintf("Unknown command \"%s\".", command))
+		return
+	}
+
+	userID := s.UserID()
+	if s.Args.Has("user") {
+		userID = s.Args.Snowflake("user")
+	}
+	channelID := s.Event.Message.ChannelID
+	if s.Args.Has("channel") {
+		channelID = s.Args.Snowflake("channel")
+	}
+
+	// The rules of a subcommand are evaluated like on invocation.
+	inv := &Invocation{Path: strings.Fields(command)}
+	inv.Command, _, _ = Index.Resolve(inv.Path)
+	names := []string{command}
+	if inv.Command != nil {
+		names = ruleCommands(inv)
+	}
+
+	var decision common.RuleDecision
+	if !isProtectedCommand(inv.Path[0]) {
+		var err error
+		decision, err = evaluateACL(s, names, userID, channelID)
+		if err != nil {
+			s.Fail(err)
+			return
+		}
+	} else {
+		decision.Allowed = true
+	}
+
+	verdict := "can"
+	if !decision.Allowed {
+		verdict = "cannot"
+	}
+	s.Reply(fmt.Sprintf(
+		"<@%s> %s use `%s` in <#%s> (%s).",
+		userID,
+		verdict,
+		command,
+		channelID,
+		describeDecision(decision),
+	))
+}
+
+func (*aclTest) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "command",
+				Description: "Name or alias of the command, command group or subcommand",
+				Parse:       parseRuleCommand,
+			},
+			{
+				Name:        "user",
+				Description: "User to check, defaults to you",
+				Type:        common.ArgumentUser,
+				Optional:    true,
+			},
+			{
+				Name:        "channel",
+				Description: "Channel to check, defaults to the current channel",
+				Type:        common.ArgumentChannel,
+				Optional:    true,
+			},
+		},
+		Notes: []common.UsageNote{
+			{
+				Name:  "Exemptions",
+				Value: "The bot's developer and the commands `help`, `commands` and `acl` are exempt from all rules.",
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Checking whether you can use a command here", Args: "remind"},
+			{Description: "Checking a user in a channel", Args: "reminder add @someone #general"},
+		},
+	}
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/commands"
+	"github.com/qysp/disgotify/pkg/common"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+func TestRuleCommands(t *testing.T) {
+	add := &testCommand{name: "remind"}
+	inv := &Invocation{Command: commands.Named("add", nil, add), Path: []string{"reminder", "add"}}
+	if names := ruleCommands(inv); !reflect.DeepEqual(names, []string{"reminder add", "remind", "reminder"}) {
+		t.Errorf("got %q", names)
+	}
+
+	inv = &Invocation{Command: add, Path: []string{"remind"}}
+	if names := ruleCommands(inv); !reflect.DeepEqual(names, []string{"remind"}) {
+		t.Errorf("got %q", names)
+	}
+}
+
+func TestACLMiddleware(t *testing.T) {
+	cmd := &testCommand{name: "announce"}
+	help := &testCommand{name: "help"}
+
+	invoke := func(c *testCommand, userID disgord.Snowflake) []string {
+		session := &fakeSession{}
+		dispatch(&Invocation{State: newTestState(session, userID, "+"+c.name), Command: c, Path: []string{c.name}})
+		return session.messages()
+	}
+	addRule := func(rule *models.CommandRule) func() {
+		rule.GuildID = testGuild
+		if err := common.SetCommandRule(rule); err != nil {
+			t.Fatal(err)
+		}
+		return func() { common.DeleteCommandRule(testGuild, rule.ID) }
+	}
+
+	removeUser := addRule(&models.CommandRule{Command: "announce", TargetType: models.RuleTargetUser, TargetID: testUser})
+	if messages := invoke(cmd, testUser); cmd.executions() != 0 || strings.Join(messages, "") != "<@2> You are not allowed to use this command." {
+		t.Errorf("a denied user must not run the command, got %q", messages)
+	}
+	if invoke(cmd, testOwner); cmd.executions() != 1 {
+		t.Error("bot owners are exempt from command rules")
+	}
+	removeUser()
+
+	defer addRule(&models.CommandRule{Command: "announce", TargetType: models.RuleTargetChannel, TargetID: testChannel})()
+	if messages := invoke(cmd, testUser); cmd.executions() != 1 || strings.Join(messages, "") != "<@2> This command cannot be used in this channel." {
+		t.Errorf("a denied channel must not run the command, got %q", messages)
+	}
+
+	defer addRule(&models.CommandRule{Command: "help", TargetType: models.RuleTargetUser, TargetID: testUser})()
+	if invoke(help, testUser); help.executions() != 1 {
+		t.Error("protected commands are exempt from command rules")
+	}
+}
+
+func TestACLAddLimit(t *testing.T) {
+	announce := &testCommand{name: "announce"}
+	defer useTestIndex(announce, newACLGroup())()
+	defer common.DB.Unscoped().Where("guild_id = ?", testGuild).Delete(&models.CommandRule{})
+
+	for i := 0; i < maxCommandRules; i++ {
+		rule := &models.CommandRule{GuildID: testGuild, Command: "announce", TargetType: models.RuleTargetUser, TargetID: disgord.Snowflake(1000 + i)}
+		if err := common.SetCommandRule(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	invoke := func(args ...string) string {
+		session := &fakeSession{}
+		inv := &Invocation{State: newTestState(session, testOwner, "+acl add "+strings.Join(args, " ")), Command: &aclAdd{}, Path: []string{"acl", "add"}, Args: args}
+		dispatch(inv)
+		return strings.Join(session.messages(), "\n")
+	}
+
+	if reply := invoke("allow", "announce", "<@1000>"); !strings.Contains(reply, "Rule #") {
+		t.Errorf("replacing a rule must not be limited, got %q", reply)
+	}
+	if reply := invoke("deny", "announce", "<@5>"); !strings.Contains(reply, "can't be more than") {
+		t.Errorf("new rules beyond the limit must be rejected, got %q", reply)
+	}
+}
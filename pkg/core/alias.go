@@ -0,0 +1,283 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/common"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+const (
+	// maxAliasDepth is the maximum number of aliases expanded for a single message.
+	maxAliasDepth = 10
+	// maxAliases is the maximum number of aliases per user or guild.
+	maxAliases = 25
+)
+
+// Alias adds, lists and removes the personal aliases of a user, or the aliases of a guild if guild is set.
+type Alias struct {
+	guild bool
+}
+
+func (c *Alias) Name() string {
+	if c.guild {
+		return "serveralias"
+	}
+	return "alias"
+}
+
+func (c *Alias) Aliases() []string {
+	if c.guild {
+		return []string{"guildalias"}
+	}
+	return []string{"shortcut"}
+}
+
+func (c *Alias) Description() string {
+	if c.guild {
+		return "Add, list and remove command aliases for everyone in this server, only admins can add and remove them."
+	}
+	return "Add, list and remove your personal command aliases."
+}
+
+func (*Alias) Category() common.CommandCategory {
+	return common.CategorySettings
+}
+
+func (*Alias) Permission() common.PermissionLevel {
+	return common.PermissionDefault
+}
+
+func (*Alias) Active() bool {
+	return true
+}
+
+func (c *Alias) Execute(s common.MessageState) {
+	var userID, guildID disgord.Snowflake
+	if c.guild {
+		if s.GuildID().Empty() {
+			s.Reply("Server aliases can only be managed in a server.")
+			return
+		}
+		guildID = s.GuildID()
+	} else {
+		userID = s.UserID()
+	}
+
+	action := s.Args.String("action")
+	if action == "list" {
+		c.list(s, userID, guildID)
+		return
+	}
+
+	if c.guild && s.UserPermission() < common.PermissionAdmin {
+		s.Reply("Only server admins can manage server aliases.")
+		return
+	}
+
+	if !s.Args.Has("name") {
+		s.Reply("Sorry, missing argument \"name\"!")
+		return
+	}
+	name := strings.ToLower(s.Args.String("name"))
+
+	existing, err := common.GetAliases(userID, guildID)
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	var alias *models.Alias
+	for i := range existing {
+		if existing[i].Name == name {
+			alias = &existing[i]
+		}
+	}
+
+	if action == "remove" {
+		if alias == nil {
+			s.Reply(fmt.Sprintf("The alias \"%s\" does not exist.", name))
+			return
+		}
+		err = common.DB.Unscoped().Delete(alias).Error
+		if err != nil {
+			s.Fail(err)
+			return
+		}
+		s.Reply(fmt.Sprintf("Removed the alias \"%s\".", name))
+		return
+	}
+
+	if !s.Args.Has("command") {
+		s.Reply("Sorry, missing argument \"command\"!")
+		return
+	}
+	expansion := normalizeExpansion(s.Args.String("command"), s.GuildPrefix())
+
+	if alias == nil && len(existing) >= maxAliases {
+		s.Reply(fmt.Sprintf("Sorry, there can't be more than %d aliases!", maxAliases))
+		return
+	}
+
+	if err := validateAlias(s, userID, guildID, name, expansion); err != nil {
+		s.Reply(fmt.Sprintf("Sorry, %s!", err.Error()))
+		return
+	}
+
+	if alias == nil {
+		alias = &models.Alias{
+			UserID:  userID,
+			GuildID: guildID,
+			Name:    name,
+		}
+	}
+	alias.Expansion = expansion
+
+	err = common.DB.Save(alias).Error
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+	prefix := s.GuildPrefix()
+	s.Reply(fmt.Sprintf("`%s%s` now runs `%s%s`.", prefix, name, prefix, expansion))
+}
+
+// list replies with the personal or guild aliases.
+func (c *Alias) list(s common.MessageState, userID, guildID disgord.Snowflake) {
+	aliases, err := common.GetAliases(userID, guildID)
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	if len(aliases) == 0 {
+		s.Reply("There are no aliases registered.")
+		return
+	}
+
+	prefix := s.GuildPrefix()
+	var fields []*disgord.EmbedField
+	for _, alias := range aliases {
+		fields = append(fields, &disgord.EmbedField{
+			Name:  prefix + alias.Name,
+			Value: prefix + alias.Expansion,
+		})
+	}
+
+	title := "List of your aliases:"
+	if c.guild {
+		title = "List of this server's aliases:"
+	}
+	s.SendEmbed(&disgord.Embed{
+		Title:  title,
+		Color:  0xe5004c,
+		Fields: fields,
+	})
+}
+
+func (c *Alias) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "action",
+				Description: "What to do with the alias",
+				Choices:     []string{"add", "remove", "list"},
+			},
+			{
+				Name:        "name",
+				Description: "Name of the alias, it cannot be the name of a command",
+				Optional:    true,
+			},
+			{
+				Name:        "command",
+				Description: "Command and arguments the alias stands for, optionally in quotes",
+				Type:        common.ArgumentText,
+				Optional:    true,
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Adding an alias", Args: "add standup \"remind weekdays 9:30 standup\""},
+			{Description: "Listing the aliases", Args: "list"},
+			{Description: "Removing an alias", Args: "remove standup"},
+		},
+	}
+}
+
+// normalizeExpansion strips surrounding quotes and the command prefix from the aliased command.
+func normalizeExpansion(expansion, prefix string) string {
+	expansion = strings.TrimSpace(expansion)
+	if len(expansion) > 1 && strings.HasPrefix(expansion, "\"") && strings.HasSuffix(expansion, "\"") {
+		expansion = expansion[1 : len(expansion)-1]
+	}
+	expansion = strings.TrimSpace(expansion)
+	if prefix != "" {
+		expansion = strings.TrimPrefix(expansion, prefix)
+	}
+	return expansion
+}
+
+// validateAlias rejects aliases shadowing commands, not resolving to a command or forming a loop.
+func validateAlias(s common.MessageState, userID, guildID disgord.Snowflake, name, expansion string) error {
+	if Index.Has(name) {
+		return fmt.Errorf("\"%s\" is already the name of a command", name)
+	}
+
+	visited := map[string]bool{name: true}
+	words := strings.Fields(expansion)
+	for depth := 0; depth < maxAliasDepth; depth++ {
+		if len(words) == 0 {
+			return fmt.Errorf("the alias needs a command")
+		}
+
+		next := strings.ToLower(words[0])
+		if Index.Has(next) {
+			return nil
+		}
+		if visited[next] {
+			return fmt.Errorf("the alias \"%s\" would create a loop", name)
+		}
+		visited[next] = true
+
+		// Personal aliases may rely on the aliases of the current guild,
+		// guild aliases only on other guild aliases (userID is empty).
+		lookupGuild := guildID
+		if !userID.Empty() {
+			lookupGuild = s.GuildID()
+		}
+		alias, err := common.FindAlias(userID, lookupGuild, next)
+		if err != nil {
+			return err
+		}
+		if alias == nil {
+			return fmt.Errorf("\"%s\" is not a command", next)
+		}
+		words = strings.Fields(alias.Expansion)
+	}
+
+	return fmt.Errorf("the alias \"%s\" expands too deeply", name)
+}
+
+// expandAliases replaces a leading alias of the user or guild with the command it stands for.
+// Commands always take precedence over aliases.
+func expandAliases(s common.MessageState, words []string) []string {
+	for depth := 0; depth < maxAliasDepth; depth++ {
+		if len(words) == 0 || Index.Has(strings.ToLower(words[0])) {
+			return words
+		}
+
+		alias, err := common.FindAlias(s.UserID(), s.GuildID(), strings.ToLower(words[0]))
+		if err != nil {
+			common.Logger.Error(err)
+			return words
+		}
+		if alias == nil {
+			return words
+		}
+
+		// Arguments following the alias are appended to its expansion.
+		words = append(strings.Split(alias.Expansion, " "), words[1:]...)
+	}
+	return words
+}
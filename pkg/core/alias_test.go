@@ -0,0 +1,129 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/commands"
+	"github.com/qysp/disgotify/pkg/common"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+// useTestIndex replaces the command index with the commands until the returned function is called.
+func useTestIndex(cmds ...commands.Command) func() {
+	previous := Index
+	Index = &commands.CommandIndex{}
+	Index.Register(cmds...)
+	return func() { Index = previous }
+}
+
+// addAlias saves an alias and returns a function deleting it.
+func addAlias(t *testing.T, userID, guildID disgord.Snowflake, name, expansion string) func() {
+	t.Helper()
+	alias := &models.Alias{UserID: userID, GuildID: guildID, Name: name, Expansion: expansion}
+	if err := common.DB.Save(alias).Error; err != nil {
+		t.Fatal(err)
+	}
+	return func() { common.DB.Unscoped().Delete(alias) }
+}
+
+func TestNormalizeExpansion(t *testing.T) {
+	tests := map[string]string{
+		`"remind weekdays 9:30 standup"`: "remind weekdays 9:30 standup",
+		` "+list" `:                      "list",
+		"+remind today 9am":              "remind today 9am",
+		`"`:                              `"`,
+	}
+	for expansion, want := range tests {
+		if got := normalizeExpansion(expansion, "+"); got != want {
+			t.Errorf("normalizeExpansion(%q) = %q, want %q", expansion, got, want)
+		}
+	}
+}
+
+func TestValidateAlias(t *testing.T) {
+	defer useTestIndex(&testCommand{name: "remind"})()
+	defer addAlias(t, testUser, 0, "standup", "remind weekdays 9:30 standup")()
+	defer addAlias(t, testUser, 0, "first", "second")()
+	defer addAlias(t, testUser, 0, "second", "first")()
+	defer addAlias(t, 0, testGuild, "team", "remind daily 10am team")()
+	state := newTestState(&fakeSession{}, testUser, "")
+
+	tests := []struct {
+		name, expansion string
+		guild           bool
+		err             string
+	}{
+		{"daily", "standup", false, ""},
+		{"mine", "team", false, ""},
+		{"remind", "remind today", false, `"remind" is already the name of a command`},
+		{"empty", "", false, "the alias needs a command"},
+		{"unknown", "dance", false, `"dance" is not a command`},
+		{"third", "first", false, `the alias "third" would create a loop`},
+		{"self", "self now", false, `the alias "self" would create a loop`},
+		// Guild aliases cannot rely on personal aliases.
+		{"server", "standup", true, `"standup" is not a command`},
+		{"server", "team", true, ""},
+	}
+
+	for _, test := range tests {
+		userID, guildID := testUser, disgord.Snowflake(0)
+		if test.guild {
+			userID, guildID = 0, testGuild
+		}
+		err := validateAlias(state, userID, guildID, test.name, test.expansion)
+		if (err == nil && test.err != "") || (err != nil && err.Error() != test.err) {
+			t.Errorf("validateAlias(%s, %q) = %v, want %q", test.name, test.expansion, err, test.err)
+		}
+	}
+}
+
+func TestExpandAliases(t *testing.T) {
+	defer useTestIndex(&testCommand{name: "remind"}, &testCommand{name: "list"})()
+	defer addAlias(t, testUser, 0, "standup", "remind weekdays 9:30")()
+	defer addAlias(t, testUser, 0, "su", "standup")()
+	defer addAlias(t, 0, testGuild, "su", "list")()
+	defer addAlias(t, 0, testGuild, "ls", "list")()
+	defer addAlias(t, 0, testGuild, "list", "remind now")()
+	state := newTestState(&fakeSession{}, testUser, "")
+
+	tests := []struct {
+		words []string
+		want  []string
+	}{
+		{[]string{"su", "standup"}, []string{"remind", "weekdays", "9:30", "standup"}},
+		{[]string{"ls"}, []string{"list"}},
+		{[]string{"list"}, []string{"list"}},
+		{[]string{"unknown", "word"}, []string{"unknown", "word"}},
+	}
+
+	for _, test := range tests {
+		if got := expandAliases(state, test.words); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("expandAliases(%q) = %q, want %q", test.words, got, test.want)
+		}
+	}
+}
+
+func TestGuildAliasPermission(t *testing.T) {
+	const (
+		moderator = disgord.Snowflake(40)
+		admin     = disgord.Snowflake(41)
+	)
+	cmd := &Alias{guild: true}
+	defer useTestIndex(&testCommand{name: "ping"}, cmd)()
+	invoke := func(session *fakeSession, userID disgord.Snowflake) string {
+		dispatch(&Invocation{State: newTestState(session, userID, "+serveralias add hi ping"), Command: cmd, Path: []string{"serveralias"}, Args: []string{"add", "hi", "ping"}})
+		messages := session.messages()
+		return messages[len(messages)-1]
+	}
+	defer common.DB.Unscoped().Where(&models.Alias{GuildID: testGuild, Name: "hi"}).Delete(&models.Alias{})
+
+	if reply := invoke(&fakeSession{permissions: disgord.PermissionManageMessages}, moderator); reply != "<@40> Only server admins can manage server aliases." {
+		t.Errorf("moderators must not add server aliases, got %q", reply)
+	}
+	if reply := invoke(&fakeSession{permissions: disgord.PermissionManageServer}, admin); !strings.Contains(reply, "now runs") {
+		t.Errorf("admins must add server aliases, got %q", reply)
+	}
+}
@@ -1,6 +1,9 @@
 package core
 
 import (
+	"context"
+	"time"
+
 	"github.com/andersfylling/disgord"
 	"github.com/qysp/disgotify/pkg/commands"
 	"github.com/qysp/disgotify/pkg/common"
@@ -13,34 +16,117 @@ var (
 
 	// Index bot command index.
 	Index *commands.CommandIndex
+
+	// rootContext is the parent of all command contexts, it is cancelled on shutdown.
+	rootContext, cancelRoot = context.WithCancel(context.Background())
 )
 
 // Start creates a new Disgord client and connects to it.
-func Start() {
+func Start(cfg *common.Config) {
+	setConfig(cfg)
+
 	Client = disgord.New(&disgord.Config{
-		BotToken: common.DiscordToken,
+		BotToken: cfg.DiscordToken,
 		Logger:   common.DisGordLogger,
 	})
 
+	// Leave guilds which are not on the guild allowlist, before connecting to receive the guilds created on connect.
+	ListenGuilds()
+
 	err := Client.Connect()
 	if err != nil {
 		common.Logger.Fatal(err)
 	}
 
+	// Remember the bot's ID to accept mentions as command prefix.
+	me, err := Client.Myself()
+	if err != nil {
+		common.Logger.Error(err)
+	} else {
+		common.BotID = me.ID
+	}
+
 	// Initialize the command index.
-	Index = commands.Init()
+	InitIndex(cfg)
+
+	// Handle commands with a bounded number of workers.
+	common.CommandQueue = common.NewWorkerPool(cfg.Workers, cfg.QueueSize)
 
 	// Listen for messages and parse them if they seem relevant.
 	go ListenMessages()
 
-	// Start the reminder service with an interval of `ReminderInterval`.
-	reminderservice.Start(Client, common.ReminderInterval)
+	// Turn the pages of paginated messages on reactions.
+	go ListenReactions()
+
+	// Start the reminder service with the configured reminder interval.
+	reminderservice.Start(Client, cfg)
+
+	// Reload the configuration on SIGHUP.
+	ListenReload()
+
+	// Receive slash commands via HTTP if configured.
+	if cfg.InteractionsAddr != "" {
+		StartInteractions(cfg.InteractionsAddr, cfg.DiscordPublicKey)
+	}
 }
 
-// StopOnInterrupt disconnect the Disgord client, stop the reminder service and close the database.
-func StopOnInterrupt() {
-	defer common.DB.Close()
-	defer reminderservice.Stop()
+// InitIndex initializes the command index including the commands living in core.
+func InitIndex(cfg *common.Config) {
+	Index = commands.Init(cfg)
+	Index.Register(
+		&Help{},
+		&CommandSettings{},
+		&Alias{},
+		&Alias{guild: true},
+		&CustomCommands{},
+		newACLGroup(),
+		&Reload{},
+	)
+}
+
+// shutdownTimeout is the time queued and running commands have to finish on shutdown,
+// and the time the remaining ones have to return once they were cancelled.
+const shutdownTimeout = 10 * time.Second
 
+// StopOnInterrupt disconnect the Disgord client, stop the interaction server and reminder service and close the database.
+// No new commands are received once the handlers are stopped, then the queued and running commands are finished.
+func StopOnInterrupt() {
 	Client.DisconnectOnInterrupt()
+	StopInteractions()
+
+	if !finishCommands(common.CommandQueue, shutdownTimeout) {
+		common.Logger.Warn("Closing the database while commands are still running")
+	}
+
+	reminderservice.Stop()
+	common.DB.Close()
+}
+
+// finishCommands stops the queue and waits until the queued and running commands are done.
+// Commands still running after timeout are cancelled, it returns false if they don't return within another timeout.
+func finishCommands(queue *common.WorkerPool, timeout time.Duration) bool {
+	defer cancelRoot()
+
+	// Once the queue is stopped no commands are started anymore, so waiting for the running ones is safe.
+	done := make(chan struct{})
+	go func() {
+		queue.Stop()
+		runningCommands.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+	}
+
+	common.Logger.Warn("Cancelling the commands which are still running")
+	cancelRoot()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
 }
@@ -0,0 +1,226 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/commands"
+	"github.com/qysp/disgotify/pkg/common"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+// protectedCommands cannot be disabled or restricted, otherwise they could lock out the guild's admins.
+var protectedCommands = []string{"help", "commands", "acl"}
+
+// CommandSettings enables, disables and restricts commands to channels in a guild.
+type CommandSettings struct{}
+
+func (*CommandSettings) Name() string {
+	return "commands"
+}
+
+func (*CommandSettings) Aliases() []string {
+	return []string{"cmds"}
+}
+
+func (*CommandSettings) Description() string {
+	return "Enable, disable or restrict commands to channels in this server."
+}
+
+func (*CommandSettings) Category() common.CommandCategory {
+	return common.CategorySettings
+}
+
+func (*CommandSettings) Permission() common.PermissionLevel {
+	return common.PermissionDefault
+}
+
+func (*CommandSettings) Active() bool {
+	return true
+}
+
+func (c *CommandSettings) Execute(s common.MessageState) {
+	if s.GuildID().Empty() {
+		s.Reply("Commands can only be configured in a server.")
+		return
+	}
+
+	if !s.Args.Has("action") {
+		c.list(s)
+		return
+	}
+
+	if s.UserPermission() < common.PermissionAdmin {
+		s.Reply("Only server admins can configure commands.")
+		return
+	}
+
+	if !s.Args.Has("command") {
+		s.Reply("Sorry, missing argument \"command\"!")
+		return
+	}
+
+	command := Index.Get(strings.ToLower(s.Args.String("command")))
+	if command == nil {
+		s.Reply(fmt.Sprintf("Unknown command \"%s\".", s.Args.String("command")))
+		return
+	}
+	name := commands.Unwrap(command).Name()
+	if isProtectedCommand(name) {
+		s.Reply(fmt.Sprintf("The command \"%s\" cannot be configured.", name))
+		return
+	}
+
+	config, err := common.GetGuildCommand(s.GuildID(), name)
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	var reply string
+	switch s.Args.String("action") {
+	case "enable":
+		err = common.DeleteGuildCommand(config)
+		reply = fmt.Sprintf("The command \"%s\" is now enabled in all channels.", name)
+	case "disable":
+		config.Disabled = true
+		err = common.SaveGuildCommand(config)
+		reply = fmt.Sprintf("The command \"%s\" is now disabled.", name)
+	case "restrict":
+		channelID := s.Event.Message.ChannelID
+		if s.Args.Has("channel") {
+			channelID = s.Args.Snowflake("channel")
+		}
+		config.Disabled = false
+		config.AddChannel(channelID)
+		err = common.SaveGuildCommand(config)
+		reply = fmt.Sprintf("The command \"%s\" can now be used in %s.", name, formatChannels(config))
+	}
+
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+	s.Reply(reply)
+}
+
+// list replies with the configured commands of the guild.
+func (*CommandSettings) list(s common.MessageState) {
+	configs, err := common.GetGuildCommands(s.GuildID())
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	if len(configs) == 0 {
+		s.Reply("All commands are enabled in all channels.")
+		return
+	}
+
+	var fields []*disgord.EmbedField
+	for _, config := range configs {
+		status := fmt.Sprintf("Restricted to %s", formatChannels(&config))
+		if config.Disabled {
+			status = "Disabled"
+		}
+		fields = append(fields, &disgord.EmbedField{
+			Name:  config.Command,
+			Value: status,
+		})
+	}
+
+	s.SendEmbed(&disgord.Embed{
+		Title:  "Configured commands of this server:",
+		Color:  0xe5004c,
+		Fields: fields,
+	})
+}
+
+func (*CommandSettings) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "action",
+				Description: "Enable (in all channels), disable or restrict a command to a channel (server admins only)",
+				Optional:    true,
+				Choices:     []string{"enable", "disable", "restrict"},
+			},
+			{
+				Name:        "command",
+				Description: "Name or alias of the command or command group",
+				Optional:    true,
+			},
+			{
+				Name:        "channel",
+				Description: "Channel to restrict the command to, defaults to the current channel",
+				Type:        common.ArgumentChannel,
+				Optional:    true,
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Listing the configured commands"},
+			{Description: "Disabling a command", Args: "disable ping"},
+			{Description: "Restricting a command to a channel", Args: "restrict remind #reminders"},
+			{Description: "Enabling a command in all channels again", Args: "enable remind"},
+		},
+	}
+}
+
+// GuildCommandMiddleware stops invocations of commands which are disabled in the guild or restricted to other channels.
+// A command is affected by its own configuration and the one of its command group.
+func GuildCommandMiddleware(next Handler) Handler {
+	return func(inv *Invocation) {
+		if inv.State.GuildID().Empty() {
+			next(inv)
+			return
+		}
+
+		names := []string{inv.Path[0]}
+		if name := commands.Unwrap(inv.Command).Name(); name != inv.Path[0] {
+			names = append(names, name)
+		}
+
+		for _, name := range names {
+			if isProtectedCommand(name) {
+				continue
+			}
+
+			config, err := common.GetGuildCommand(inv.State.GuildID(), name)
+			if err != nil {
+				common.Logger.Error(err)
+				continue
+			}
+
+			if config.Disabled {
+				inv.State.Reply("This command is disabled in this server.")
+				return
+			}
+			if !config.AllowsChannel(inv.State.Event.Message.ChannelID) {
+				inv.State.Reply(fmt.Sprintf("This command can only be used in %s.", formatChannels(config)))
+				return
+			}
+		}
+
+		next(inv)
+	}
+}
+
+// isProtectedCommand returns a bool which indicates whether the command cannot be configured.
+func isProtectedCommand(name string) bool {
+	for _, protected := range protectedCommands {
+		if name == protected {
+			return true
+		}
+	}
+	return false
+}
+
+// formatChannels returns the mentions of the channels a command is restricted to.
+func formatChannels(config *models.GuildCommand) string {
+	var mentions []string
+	for _, id := range config.ChannelIDs() {
+		mentions = append(mentions, fmt.Sprintf("<#%s>", id))
+	}
+	return strings.Join(mentions, ", ")
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/qysp/disgotify/pkg/commands"
+	"github.com/qysp/disgotify/pkg/common"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+func TestGuildCommandMiddleware(t *testing.T) {
+	session := &fakeSession{}
+	add := &testCommand{name: "remind"}
+	group := commands.NewGroup("reminder", nil, "", common.CategoryReminders, commands.Named("add", nil, add))
+
+	invoke := func(path ...string) {
+		cmd, _, _ := (&commands.CommandIndex{path[0]: group, "remind": add}).Resolve(path)
+		dispatch(&Invocation{State: newTestState(session, testUser, "+"+strings.Join(path, " ")), Command: cmd, Path: path})
+	}
+	save := func(config *models.GuildCommand) {
+		if err := common.SaveGuildCommand(config); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lastMessage := func() string {
+		messages := session.messages()
+		return messages[len(messages)-1]
+	}
+
+	// Disabling the command also disables it as subcommand.
+	disabled := &models.GuildCommand{GuildID: testGuild, Command: "remind", Disabled: true}
+	save(disabled)
+	invoke("remind")
+	invoke("reminder", "add")
+	if add.executions() != 0 || lastMessage() != "<@2> This command is disabled in this server." {
+		t.Errorf("a disabled command must not run, got %q", session.messages())
+	}
+	common.DeleteGuildCommand(disabled)
+
+	// Restricting the group restricts its subcommands.
+	restricted := &models.GuildCommand{GuildID: testGuild, Command: "reminder"}
+	restricted.AddChannel(testChannel + 1)
+	save(restricted)
+	invoke("reminder", "add")
+	if add.executions() != 0 || !strings.Contains(lastMessage(), "can only be used in <#201>") {
+		t.Errorf("a command restricted to another channel must not run, got %q", lastMessage())
+	}
+	invoke("remind")
+	if add.executions() != 1 {
+		t.Error("the group's restriction must not affect the command itself")
+	}
+
+	restricted.AddChannel(testChannel)
+	save(restricted)
+	invoke("reminder", "add")
+	if add.executions() != 2 {
+		t.Error("a command must run in the channels it is restricted to")
+	}
+	common.DeleteGuildCommand(restricted)
+}
+
+func TestProtectedCommands(t *testing.T) {
+	session := &fakeSession{}
+	help := &testCommand{name: "help"}
+	config := &models.GuildCommand{GuildID: testGuild, Command: "help", Disabled: true}
+	if err := common.SaveGuildCommand(config); err != nil {
+		t.Fatal(err)
+	}
+	defer common.DeleteGuildCommand(config)
+
+	dispatch(&Invocation{State: newTestState(session, testUser, "+help"), Command: help, Path: []string{"help"}})
+	if help.executions() != 1 {
+		t.Error("protected commands cannot be disabled")
+	}
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/commands"
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+// cooldownBucket represents the uses of a command within the current period of a cooldown.
+type cooldownBucket struct {
+	uses  int
+	reset time.Time
+}
+
+var (
+	cooldownBuckets = map[string]*cooldownBucket{}
+	cooldownMutex   sync.Mutex
+)
+
+// CooldownMiddleware stops invocations exceeding a cooldown of the command, developers are exempt.
+func CooldownMiddleware(next Handler) Handler {
+	return func(inv *Invocation) {
+		if inv.State.UserPermission() >= common.PermissionDeveloper {
+			next(inv)
+			return
+		}
+
+		if wait := useCooldowns(inv); wait > 0 {
+			inv.State.Reply(fmt.Sprintf("Please slow down, try again in %s.", formatWait(wait)))
+			return
+		}
+		next(inv)
+	}
+}
+
+// commandCooldowns returns the configured cooldowns of a command, falling back to the declared ones.
+func commandCooldowns(cfg *common.Config, cmd commands.Command) []common.Cooldown {
+	if cooldowns, ok := cfg.Cooldowns[cmd.Name()]; ok {
+		return cooldowns
+	}
+	if c, ok := cmd.(commands.CooldownCommand); ok {
+		return c.Cooldowns()
+	}
+	return nil
+}
+
+// useCooldowns counts the invocation against all cooldowns of the command.
+// If any cooldown is exceeded, nothing is counted and the time until it resets is returned.
+func useCooldowns(inv *Invocation) time.Duration {
+	// Subcommands share the cooldowns of the command they were registered from.
+	cmd := commands.Unwrap(inv.Command)
+	cooldowns := commandCooldowns(inv.State.Config, cmd)
+	if len(cooldowns) == 0 {
+		return 0
+	}
+
+	cooldownMutex.Lock()
+	defer cooldownMutex.Unlock()
+
+	now := time.Now()
+	pruneCooldowns(now)
+
+	var buckets []*cooldownBucket
+	for i, cooldown := range cooldowns {
+		key := fmt.Sprintf("%s/%d/%d/%s", cmd.Name(), i, cooldown.Scope, cooldownSubject(inv.State, cooldown.Scope))
+		bucket, ok := cooldownBuckets[key]
+		if !ok || !now.Before(bucket.reset) {
+			bucket = &cooldownBucket{reset: now.Add(cooldown.Period)}
+			cooldownBuckets[key] = bucket
+		}
+
+		if bucket.uses >= cooldown.Uses {
+			return bucket.reset.Sub(now)
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	for _, bucket := range buckets {
+		bucket.uses++
+	}
+	return 0
+}
+
+// cooldownSubject returns the ID of whoever shares a cooldown of the scope.
+// In a DM, channel and guild cooldowns apply to the DM channel.
+func cooldownSubject(s common.MessageState, scope common.CooldownScope) disgord.Snowflake {
+	switch scope {
+	case common.CooldownChannel:
+		return s.Event.Message.ChannelID
+	case common.CooldownGuild:
+		if s.GuildID().Empty() {
+			return s.Event.Message.ChannelID
+		}
+		return s.GuildID()
+	}
+	return s.UserID()
+}
+
+// pruneCooldowns removes expired buckets once there are more than a few, needs the lock.
+func pruneCooldowns(now time.Time) {
+	if len(cooldownBuckets) < 1000 {
+		return
+	}
+	for key, bucket := range cooldownBuckets {
+		if !now.Before(bucket.reset) {
+			delete(cooldownBuckets, key)
+		}
+	}
+}
+
+// formatWait formats the remaining time of a cooldown in whole seconds, e.g. "12s".
+func formatWait(wait time.Duration) string {
+	if wait < time.Second {
+		return "1s"
+	}
+	return wait.Round(time.Second).String()
+}
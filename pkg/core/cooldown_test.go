@@ -0,0 +1,69 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+func TestCooldownMiddleware(t *testing.T) {
+	defer setConfig(currentConfig())
+	cfg := testConfig()
+	cfg.Cooldowns = map[string][]common.Cooldown{
+		"limited": {{Scope: common.CooldownUser, Uses: 2, Period: time.Minute}},
+	}
+	setConfig(cfg)
+
+	session := &fakeSession{}
+	cmd := &testCommand{
+		name:      "limited",
+		arguments: []common.Argument{{Name: "count", Type: common.ArgumentInteger}},
+	}
+	invoke := func(id disgord.Snowflake, arg string) {
+		dispatch(&Invocation{State: newTestState(session, id, "+limited "+arg), Command: cmd, Path: []string{"limited"}, Args: []string{arg}})
+	}
+
+	invoke(testUser, "1")
+	invoke(testUser, "2")
+	if cmd.executions() != 2 {
+		t.Fatalf("got %d executions, want 2", cmd.executions())
+	}
+
+	invoke(testUser, "3")
+	messages := session.messages()
+	if cmd.executions() != 2 || !strings.Contains(messages[len(messages)-1], "Please slow down, try again in 1m0s.") {
+		t.Errorf("the third use must be rejected, got %q", messages[len(messages)-1])
+	}
+
+	// Invalid arguments count against the cooldown, otherwise the usage replies could be spammed.
+	invoke(testUser+1, "one")
+	invoke(testUser+1, "two")
+	invoke(testUser+1, "three")
+	messages = session.messages()
+	if len(messages) != 4 || !strings.Contains(messages[3], "<@3> Please slow down") {
+		t.Errorf("the third invalid use must be rejected, got %q", messages)
+	}
+
+	// Owners are exempt.
+	invoke(testOwner, "1")
+	invoke(testOwner, "2")
+	invoke(testOwner, "3")
+	if cmd.executions() != 5 {
+		t.Errorf("owners must be exempt, got %d executions", cmd.executions())
+	}
+}
+
+func TestFormatWait(t *testing.T) {
+	for wait, want := range map[time.Duration]string{
+		100 * time.Millisecond:  "1s",
+		1400 * time.Millisecond: "1s",
+		90 * time.Second:        "1m30s",
+	} {
+		if got := formatWait(wait); got != want {
+			t.Errorf("formatWait(%s) = %s, want %s", wait, got, want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andersfylling/disgord"
+	"github.com/andersfylling/disgord/logger"
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+func TestMain(m *testing.M) {
+	common.InitNopLogger()
+
+	dir, err := ioutil.TempDir("", "disgotify")
+	if err != nil {
+		panic(err)
+	}
+	common.InitDB(dir)
+	setConfig(testConfig())
+
+	code := m.Run()
+
+	common.DB.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+const (
+	testGuild   = disgord.Snowflake(100)
+	testChannel = disgord.Snowflake(200)
+	testOwner   = disgord.Snowflake(1)
+	testUser    = disgord.Snowflake(2)
+	dmOffset    = disgord.Snowflake(1000)
+)
+
+// testConfig returns a config with testOwner as the bot's owner.
+func testConfig() *common.Config {
+	return &common.Config{
+		CommandPrefix:  "+",
+		OwnerIDs:       []disgord.Snowflake{testOwner},
+		CommandTimeout: time.Second,
+	}
+}
+
+// fakeSession records the sent messages, the other methods of disgord.Session are not implemented.
+type fakeSession struct {
+	disgord.Session
+
+	mu   sync.Mutex
+	sent []*disgord.CreateMessageParams
+	// channels are the channels of the sent messages.
+	channels []disgord.Snowflake
+	// permissions are the Discord permissions of every member.
+	permissions disgord.PermissionBits
+	// left are the guilds the bot left.
+	left []disgord.Snowflake
+	// guilds are the guilds the bot is in.
+	guilds []disgord.Snowflake
+}
+
+func (s *fakeSession) SendMsg(channelID disgord.Snowflake, data ...interface{}) (*disgord.Message, error) {
+	params := &disgord.CreateMessageParams{}
+	for _, d := range data {
+		switch v := d.(type) {
+		case string:
+			params.Content = v
+		case *disgord.CreateMessageParams:
+			params = v
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, params)
+	s.channels = append(s.channels, channelID)
+	return &disgord.Message{ID: disgord.Snowflake(len(s.sent)), ChannelID: channelID, Content: params.Content}, nil
+}
+
+func (s *fakeSession) Logger() logger.Logger {
+	return logger.Empty{}
+}
+
+// CreateDM returns a DM channel whose ID is the recipient's ID plus dmOffset.
+func (s *fakeSession) CreateDM(recipientID disgord.Snowflake, flags ...disgord.Flag) (*disgord.Channel, error) {
+	return &disgord.Channel{ID: recipientID + dmOffset, Type: disgord.ChannelTypeDM}, nil
+}
+
+// dms returns the contents of the messages sent to the user.
+func (s *fakeSession) dms(userID disgord.Snowflake) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var contents []string
+	for i, params := range s.sent {
+		if s.channels[i] == userID+dmOffset {
+			contents = append(contents, params.Content)
+		}
+	}
+	return contents
+}
+
+func (s *fakeSession) GetGuild(id disgord.Snowflake, flags ...disgord.Flag) (*disgord.Guild, error) {
+	return &disgord.Guild{ID: id, OwnerID: testOwner}, nil
+}
+
+func (s *fakeSession) GetMember(guildID, userID disgord.Snowflake, flags ...disgord.Flag) (*disgord.Member, error) {
+	return &disgord.Member{GuildID: guildID, User: &disgord.User{ID: userID}}, nil
+}
+
+func (s *fakeSession) GetGuildRoles(guildID disgord.Snowflake, flags ...disgord.Flag) ([]*disgord.Role, error) {
+	return []*disgord.Role{{ID: guildID, Permissions: s.permissions}}, nil
+}
+
+func (s *fakeSession) LeaveGuild(id disgord.Snowflake, flags ...disgord.Flag) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.left = append(s.left, id)
+	return nil
+}
+
+func (s *fakeSession) GetConnectedGuilds() []disgord.Snowflake {
+	return s.guilds
+}
+
+// messages returns the contents of all sent messages.
+func (s *fakeSession) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var contents []string
+	for _, params := range s.sent {
+		content := params.Content
+		if params.Embed != nil {
+			content += params.Embed.Title
+		}
+		contents = append(contents, content)
+	}
+	return contents
+}
+
+// newTestState returns the state of a message of the user in the test guild.
+func newTestState(session disgord.Session, userID disgord.Snowflake, content string) common.MessageState {
+	return common.MessageState{
+		Session: session,
+		Event: &disgord.MessageCreate{
+			Message: &disgord.Message{
+				Content:   content,
+				ChannelID: testChannel,
+				GuildID:   testGuild,
+				Author:    &disgord.User{ID: userID},
+			},
+		},
+		Config: currentConfig(),
+	}
+}
+
+// testCommand is a command which records its executions.
+type testCommand struct {
+	name       string
+	permission common.PermissionLevel
+	arguments  []common.Argument
+	execute    func(s common.MessageState)
+
+	mu       sync.Mutex
+	executed int
+}
+
+func (c *testCommand) Name() string                       { return c.name }
+func (c *testCommand) Aliases() []string                  { return []string{} }
+func (c *testCommand) Description() string                { return fmt.Sprintf("The %s command.", c.name) }
+func (c *testCommand) Category() common.CommandCategory   { return common.CategoryGeneral }
+func (c *testCommand) Permission() common.PermissionLevel { return c.permission }
+func (c *testCommand) Active() bool                       { return true }
+func (c *testCommand) Usage() common.CommandUsage         { return common.CommandUsage{Arguments: c.arguments} }
+
+func (c *testCommand) Execute(s common.MessageState) {
+	c.mu.Lock()
+	c.executed++
+	c.mu.Unlock()
+	if c.execute != nil {
+		c.execute(s)
+	}
+}
+
+// executions returns how often the command was executed.
+func (c *testCommand) executions() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.executed
+}
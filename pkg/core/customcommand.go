@@ -0,0 +1,487 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/common"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+const (
+	// maxCustomCommands is the maximum number of custom commands per guild.
+	maxCustomCommands = 50
+	// maxCustomTemplate is the maximum length of a custom command's template.
+	maxCustomTemplate = 1000
+	// maxCustomOutput is the maximum length of a rendered template, which is Discord's message limit.
+	maxCustomOutput = 2000
+	// customTimeout is the time a template has to render.
+	customTimeout = 2 * time.Second
+)
+
+// CustomCommands adds, edits, lists and deletes the custom text commands of a guild.
+type CustomCommands struct{}
+
+func (*CustomCommands) Name() string {
+	return "customcmd"
+}
+
+func (*CustomCommands) Aliases() []string {
+	return []string{"customcommand", "cc"}
+}
+
+func (*CustomCommands) Description() string {
+	return "Add, list, edit and delete this server's custom text commands, only admins can change them."
+}
+
+func (*CustomCommands) Category() common.CommandCategory {
+	return common.CategorySettings
+}
+
+func (*CustomCommands) Permission() common.PermissionLevel {
+	return common.PermissionDefault
+}
+
+func (*CustomCommands) Active() bool {
+	return true
+}
+
+func (c *CustomCommands) Execute(s common.MessageState) {
+	if s.GuildID().Empty() {
+		s.Reply("Custom commands can only be managed in a server.")
+		return
+	}
+
+	action := s.Args.String("action")
+	if action == "list" {
+		c.list(s)
+		return
+	}
+
+	if s.UserPermission() < common.PermissionAdmin {
+		s.Reply("Only server admins can manage custom commands.")
+		return
+	}
+
+	if !s.Args.Has("name") {
+		s.Reply("Sorry, missing argument \"name\"!")
+		return
+	}
+	name := strings.ToLower(s.Args.String("name"))
+
+	custom, err := common.GetCustomCommand(s.GuildID(), name)
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	switch action {
+	case "delete":
+		if custom == nil {
+			s.Reply(fmt.Sprintf("The custom command \"%s\" does not exist.", name))
+			return
+		}
+		err = common.DB.Unscoped().Delete(custom).Error
+		if err != nil {
+			s.Fail(err)
+			return
+		}
+		s.Reply(fmt.Sprintf("Deleted the custom command \"%s\".", name))
+		return
+
+	case "add":
+		if custom != nil {
+			s.Reply(fmt.Sprintf("The custom command \"%s\" already exists, use `edit` to change it.", name))
+			return
+		}
+		if Index.Has(name) {
+			s.Reply(fmt.Sprintf("Sorry, \"%s\" is already the name of a command!", name))
+			return
+		}
+		existing, err := common.GetCustomCommands(s.GuildID())
+		if err != nil {
+			s.Fail(err)
+			return
+		}
+		if len(existing) >= maxCustomCommands {
+			s.Reply(fmt.Sprintf("Sorry, there can't be more than %d custom commands!", maxCustomCommands))
+			return
+		}
+		custom = &models.CustomCommand{
+			GuildID:   s.GuildID(),
+			Name:      name,
+			CreatedBy: s.UserID(),
+		}
+
+	case "edit":
+		if custom == nil {
+			s.Reply(fmt.Sprintf("The custom command \"%s\" does not exist.", name))
+			return
+		}
+	}
+
+	if !s.Args.Has("template") {
+		s.Reply("Sorry, missing argument \"template\"!")
+		return
+	}
+	text := unquote(s.Args.String("template"))
+	if len(text) > maxCustomTemplate {
+		s.Reply(fmt.Sprintf("Sorry, the template can't be longer than %d characters!", maxCustomTemplate))
+		return
+	}
+	if _, err := parseCustomTemplate(text, customFuncs(s.Context(), s, nil)); err != nil {
+		s.Reply(fmt.Sprintf("Sorry, the template is invalid: %s", err.Error()))
+		return
+	}
+	custom.Template = text
+
+	err = common.DB.Save(custom).Error
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+	s.Reply(fmt.Sprintf("Saved the custom command `%s%s`.", s.GuildPrefix(), name))
+}
+
+// list replies with the custom commands of the guild.
+func (*CustomCommands) list(s common.MessageState) {
+	customCommands, err := common.GetCustomCommands(s.GuildID())
+	if err != nil {
+		s.Fail(err)
+		return
+	}
+
+	if len(customCommands) == 0 {
+		s.Reply("There are no custom commands in this server.")
+		return
+	}
+
+	prefix := s.GuildPrefix()
+	var fields []*disgord.EmbedField
+	for _, custom := range customCommands {
+		fields = append(fields, &disgord.EmbedField{
+			Name:  prefix + custom.Name,
+			Value: fmt.Sprintf("```%s```", strings.Replace(custom.Template, "`", "'", -1)),
+		})
+	}
+
+	s.SendPaginated(&disgord.Embed{
+		Title:  "List of this server's custom commands:",
+		Color:  0xe5004c,
+		Fields: fields,
+	})
+}
+
+func (*CustomCommands) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "action",
+				Description: "What to do with the custom command",
+				Choices:     []string{"add", "edit", "delete", "list"},
+			},
+			{
+				Name:        "name",
+				Description: "Name of the custom command, it cannot be the name of a command",
+				Optional:    true,
+			},
+			{
+				Name:        "template",
+				Description: "Reply of the custom command, optionally in quotes",
+				Type:        common.ArgumentText,
+				Optional:    true,
+			},
+		},
+		Notes: []common.UsageNote{
+			{
+				Name: "Templates",
+				Value: "Replies are Go templates: `{{user.mention}}`, `{{user.name}}`, `{{guild.name}}`, " +
+					"`{{channel}}` (this channel), `{{channel \"rules\"}}`, `{{arg 0}}`, `{{args}}` and " +
+					"`{{now.Format \"15:04\"}}` are available, loops and nested templates are not.",
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Adding a custom command", Args: "add rules \"Please read {{channel \\\"rules\\\"}}, {{user.mention}}\""},
+			{Description: "Listing the custom commands", Args: "list"},
+			{Description: "Deleting a custom command", Args: "delete rules"},
+		},
+	}
+}
+
+// customCommand represents a stored custom command registered for a single invocation.
+type customCommand struct {
+	model *models.CustomCommand
+}
+
+// findCustomCommand returns the custom command of the guild by name or nil.
+func findCustomCommand(s common.MessageState, name string) *customCommand {
+	custom, err := common.GetCustomCommand(s.GuildID(), strings.ToLower(name))
+	if err != nil {
+		common.Logger.Error(err)
+		return nil
+	}
+	if custom == nil {
+		return nil
+	}
+	return &customCommand{model: custom}
+}
+
+func (c *customCommand) Name() string {
+	return c.model.Name
+}
+
+func (*customCommand) Aliases() []string {
+	return []string{}
+}
+
+func (*customCommand) Description() string {
+	return "Custom command of this server."
+}
+
+func (*customCommand) Category() common.CommandCategory {
+	return common.CategoryGeneral
+}
+
+func (*customCommand) Permission() common.PermissionLevel {
+	return common.PermissionDefault
+}
+
+func (*customCommand) Active() bool {
+	return true
+}
+
+func (c *customCommand) Execute(s common.MessageState) {
+	out, err := renderCustomCommand(s, c.model.Template, strings.Fields(s.Args.String("args")))
+	if err != nil {
+		s.Reply(fmt.Sprintf("Sorry, the custom command \"%s\" failed: %s", c.Name(), err.Error()))
+		return
+	}
+	if strings.TrimSpace(out) == "" {
+		return
+	}
+	// Templates echo arguments, which must not ping @everyone or roles.
+	s.SendRestricted(out)
+}
+
+func (*customCommand) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "args",
+				Description: "Arguments available to the template",
+				Type:        common.ArgumentText,
+				Optional:    true,
+			},
+		},
+	}
+}
+
+// customFuncs returns the functions available in templates, they only expose plain values.
+// Functions making requests fail once ctx is done, which stops the rendering.
+func customFuncs(ctx context.Context, s common.MessageState, args []string) template.FuncMap {
+	return template.FuncMap{
+		"user": func() map[string]string {
+			author := s.Event.Message.Author
+			return map[string]string{
+				"id":      author.ID.String(),
+				"name":    author.Username,
+				"mention": author.Mention(),
+			}
+		},
+		"guild": func() (map[string]string, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			guild := map[string]string{"id": s.GuildID().String()}
+			if g, err := s.Session.GetGuild(s.GuildID()); err == nil {
+				guild["name"] = g.Name
+			}
+			return guild, nil
+		},
+		"channel": func(name ...string) (string, error) {
+			if len(name) == 0 {
+				return fmt.Sprintf("<#%s>", s.Event.Message.ChannelID), nil
+			}
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+			channels, err := s.Session.GetGuildChannels(s.GuildID())
+			if err == nil {
+				for _, ch := range channels {
+					if strings.EqualFold(ch.Name, name[0]) {
+						return fmt.Sprintf("<#%s>", ch.ID), nil
+					}
+				}
+			}
+			return "#" + name[0], nil
+		},
+		"args": func() []string {
+			return args
+		},
+		"arg": func(i int) string {
+			if i < 0 || i >= len(args) {
+				return ""
+			}
+			return args[i]
+		},
+		"now": time.Now,
+		// printf replaces the built-in one, whose padding could allocate arbitrary amounts of memory.
+		"printf": func(format string, a ...interface{}) (string, error) {
+			if hasHugeWidth(format) {
+				return "", fmt.Errorf("the width or precision in \"%s\" is too large", format)
+			}
+			return fmt.Sprintf(format, a...), nil
+		},
+	}
+}
+
+// maxFormatWidth is the maximum width and precision of printf verbs in templates.
+const maxFormatWidth = 999
+
+// formatVerbRegex matches escaped percent signs and the flags, width and precision of format verbs.
+var formatVerbRegex = regexp.MustCompile(`%%|%[-+# 0]*(?:\[\d+\])?(\*|\d+)?(?:\.(?:\[\d+\])?(\*|\d+)?)?`)
+
+// hasHugeWidth returns whether a verb of the format has a width or precision above maxFormatWidth or given as argument.
+func hasHugeWidth(format string) bool {
+	for _, match := range formatVerbRegex.FindAllStringSubmatch(format, -1) {
+		for _, number := range match[1:] {
+			if number == "" {
+				continue
+			}
+			if n, err := strconv.Atoi(number); err != nil || n > maxFormatWidth {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseCustomTemplate parses a template and rejects constructs which could run for long.
+func parseCustomTemplate(text string, funcs template.FuncMap) (*template.Template, error) {
+	t, err := template.New("custom").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Templates()) > 1 {
+		return nil, fmt.Errorf("nested templates are not allowed")
+	}
+	if err := checkTemplateNode(t.Tree.Root); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// checkTemplateNode rejects loops and nested templates anywhere below node.
+func checkTemplateNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkTemplateNode(child); err != nil {
+				return err
+			}
+		}
+	case *parse.IfNode:
+		return checkBranchNode(&n.BranchNode)
+	case *parse.WithNode:
+		return checkBranchNode(&n.BranchNode)
+	case *parse.RangeNode:
+		return fmt.Errorf("loops are not allowed")
+	case *parse.TemplateNode:
+		return fmt.Errorf("nested templates are not allowed")
+	}
+	return nil
+}
+
+// checkBranchNode checks both branches of an if or with.
+func checkBranchNode(n *parse.BranchNode) error {
+	if err := checkTemplateNode(n.List); err != nil {
+		return err
+	}
+	return checkTemplateNode(n.ElseList)
+}
+
+// renderCustomCommand renders a custom command's template within the output and time limits.
+func renderCustomCommand(s common.MessageState, text string, args []string) (string, error) {
+	// The rendering stops at the next request or write once the context is done.
+	ctx, cancel := context.WithTimeout(s.Context(), customTimeout)
+	defer cancel()
+
+	t, err := parseCustomTemplate(text, customFuncs(ctx, s, args))
+	if err != nil {
+		return "", err
+	}
+
+	// The same context is available as data, e.g. {{.author.mention}}.
+	data := map[string]interface{}{
+		"author": map[string]string{
+			"id":      s.UserID().String(),
+			"name":    s.Event.Message.Author.Username,
+			"mention": s.Event.Message.Author.Mention(),
+		},
+		"channel": map[string]string{
+			"id":      s.Event.Message.ChannelID.String(),
+			"mention": fmt.Sprintf("<#%s>", s.Event.Message.ChannelID),
+		},
+		"guild": map[string]string{
+			"id": s.GuildID().String(),
+		},
+		"args": args,
+		"now":  time.Now(),
+	}
+
+	out := &limitedWriter{ctx: ctx, limit: maxCustomOutput}
+	done := make(chan error, 1)
+	go func() {
+		done <- t.Execute(out, data)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return "", err
+		}
+		return out.String(), nil
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("rendering took too long")
+		}
+		return "", ctx.Err()
+	}
+}
+
+// limitedWriter represents a buffer failing writes beyond its limit or once ctx is done, which stops the template execution.
+type limitedWriter struct {
+	bytes.Buffer
+	ctx   context.Context
+	limit int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if w.Len()+len(p) > w.limit {
+		return 0, fmt.Errorf("the reply is longer than %d characters", w.limit)
+	}
+	return w.Buffer.Write(p)
+}
+
+// unquote strips the quotes surrounding a text argument and unescapes the quotes within.
+func unquote(text string) string {
+	text = strings.TrimSpace(text)
+	if len(text) > 1 && strings.HasPrefix(text, "\"") && strings.HasSuffix(text, "\"") {
+		text = strings.Replace(text[1:len(text)-1], "\\\"", "\"", -1)
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/common"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+func TestHasHugeWidth(t *testing.T) {
+	tests := []struct {
+		format string
+		huge   bool
+	}{
+		{"%s", false},
+		{"%5d", false},
+		{"%999d", false},
+		{"%0999d", false},
+		{"%-999s", false},
+		{"%.999f", false},
+		{"%%5000d", false},
+		{"%1000d", true},
+		{"%01000d", true},
+		{"%-+ #01000d", true},
+		{"%.1000f", true},
+		{"%5.1000f", true},
+		{"%*d", true},
+		{"%.*f", true},
+		{"%[1]*d", true},
+		{"%[2]1000d", true},
+		{"%99999999999999999999d", true},
+		{"%s %1000s", true},
+	}
+
+	for _, test := range tests {
+		if huge := hasHugeWidth(test.format); huge != test.huge {
+			t.Errorf("hasHugeWidth(%q) = %v, want %v", test.format, huge, test.huge)
+		}
+	}
+}
+
+func TestParseCustomTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		valid    bool
+	}{
+		{"Hello {{user.mention}}!", true},
+		{"{{if args}}{{arg 0}}{{else}}nothing{{end}}", true},
+		{"{{range args}}{{.}}{{end}}", false},
+		{"{{if args}}{{range args}}{{.}}{{end}}{{end}}", false},
+		{"{{define \"x\"}}x{{end}}{{template \"x\"}}", false},
+		{"{{template \"custom\"}}", false},
+		{"{{unknown}}", false},
+	}
+
+	for _, test := range tests {
+		_, err := parseCustomTemplate(test.template, customFuncs(context.Background(), newTestState(&fakeSession{}, testUser, ""), nil))
+		if (err == nil) != test.valid {
+			t.Errorf("parseCustomTemplate(%q) = %v, want valid %v", test.template, err, test.valid)
+		}
+	}
+}
+
+func TestRenderCustomCommand(t *testing.T) {
+	s := newTestState(&fakeSession{}, testUser, "+greet a b")
+
+	tests := []struct {
+		template string
+		out      string
+		fails    bool
+	}{
+		{"Hi {{user.mention}}, {{arg 1}}{{arg 5}}", "Hi <@2>, b", false},
+		{"{{.author.mention}} in {{channel}}", "<@2> in <#200>", false},
+		{"{{printf \"%05d\" 42}}", "00042", false},
+		{"{{printf \"%01000d\" 42}}", "", true},
+		{strings.Repeat("x", 999) + "{{printf \"%999d\" 1}}{{printf \"%999d\" 1}}", "", true},
+	}
+
+	for _, test := range tests {
+		out, err := renderCustomCommand(s, test.template, []string{"a", "b"})
+		if (err != nil) != test.fails {
+			t.Errorf("renderCustomCommand(%q) failed with %v", test.template, err)
+			continue
+		}
+		if out != test.out {
+			t.Errorf("renderCustomCommand(%q) = %q, want %q", test.template, out, test.out)
+		}
+	}
+}
+
+// slowChannelSession represents a session whose channel requests take long.
+type slowChannelSession struct {
+	fakeSession
+	requests int32
+}
+
+func (s *slowChannelSession) GetGuildChannels(guildID disgord.Snowflake, flags ...disgord.Flag) ([]*disgord.Channel, error) {
+	atomic.AddInt32(&s.requests, 1)
+	time.Sleep(100 * time.Millisecond)
+	return nil, nil
+}
+
+func TestRenderCustomCommandCancelled(t *testing.T) {
+	session := &slowChannelSession{}
+	s := newTestState(session, testUser, "+channels")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s.Ctx = ctx
+
+	if _, err := renderCustomCommand(s, `{{channel "a"}} {{channel "b"}} {{channel "c"}}`, nil); err == nil {
+		t.Fatal("the rendering must stop once the context is done")
+	}
+
+	// The rendering still running in the background must not make further requests.
+	time.Sleep(300 * time.Millisecond)
+	if requests := atomic.LoadInt32(&session.requests); requests != 1 {
+		t.Errorf("got %d channel requests, want 1", requests)
+	}
+}
+
+func TestCustomCommandMentions(t *testing.T) {
+	custom := &customCommand{model: &models.CustomCommand{Name: "echo", Template: "{{args}}"}}
+
+	session := &fakeSession{}
+	s := newTestState(session, testUser, "+echo @everyone <@&100> <@2>")
+	s.Args = common.Arguments{"args": "@everyone <@&100> <@2>"}
+	custom.Execute(s)
+
+	want := "[@\u200beveryone <@\u200b&100> <@2>]"
+	if messages := session.messages(); len(messages) != 1 || messages[0] != want {
+		t.Errorf("got %q, want %q", messages, want)
+	}
+
+	interaction := &interactionSession{Session: &fakeSession{}, channelID: testChannel}
+	s = newTestState(interaction, testUser, "/echo")
+	s.Args = common.Arguments{"args": "@everyone"}
+	custom.Execute(s)
+
+	data := interaction.response().Data
+	if data.Content != "[@everyone]" || data.AllowedMentions != common.UserMentionsOnly {
+		t.Errorf("got %+v, want the content with only user mentions allowed", data)
+	}
+}
+
+func TestInteractionResponseMentions(t *testing.T) {
+	interaction := &interactionSession{Session: &fakeSession{}, channelID: testChannel}
+	interaction.SendMsg(testChannel, "@everyone")
+	if data := interaction.response().Data; data.AllowedMentions != nil {
+		t.Errorf("unrestricted messages must keep Discord's default, got %+v", data.AllowedMentions)
+	}
+
+	other := &fakeSession{}
+	interaction = &interactionSession{Session: other, channelID: testChannel}
+	common.SendRestricted(interaction, disgord.Snowflake(300), "@here")
+	if messages := other.messages(); len(messages) != 1 || messages[0] != "@\u200bhere" {
+		t.Errorf("messages to other channels got %q", messages)
+	}
+}
+
+func TestCustomCommandsPermission(t *testing.T) {
+	const (
+		moderator = disgord.Snowflake(42)
+		admin     = disgord.Snowflake(43)
+	)
+	cmd := &CustomCommands{}
+	defer useTestIndex(cmd)()
+	invoke := func(session *fakeSession, userID disgord.Snowflake) string {
+		dispatch(&Invocation{State: newTestState(session, userID, "+customcmd add hello hi"), Command: cmd, Path: []string{"customcmd"}, Args: []string{"add", "hello", "hi"}})
+		messages := session.messages()
+		return messages[len(messages)-1]
+	}
+	defer common.DB.Unscoped().Where(&models.CustomCommand{GuildID: testGuild, Name: "hello"}).Delete(&models.CustomCommand{})
+
+	if reply := invoke(&fakeSession{permissions: disgord.PermissionManageMessages}, moderator); reply != "<@42> Only server admins can manage custom commands." {
+		t.Errorf("moderators must not add custom commands, got %q", reply)
+	}
+	invoke(&fakeSession{permissions: disgord.PermissionManageServer}, admin)
+	if custom, err := common.GetCustomCommand(testGuild, "hello"); err != nil || custom == nil {
+		t.Errorf("admins must add custom commands, got %v", err)
+	}
+}
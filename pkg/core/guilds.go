@@ -0,0 +1,105 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+var (
+	// dormantGuilds remembers the unlisted guilds the owners were notified about since the start.
+	dormantGuilds   = map[disgord.Snowflake]bool{}
+	dormantGuildsMu sync.Mutex
+)
+
+// ListenGuilds listens for joined guilds and leaves the ones which are not on the guild allowlist.
+// Guilds are also created on connecting, so guilds joined while the bot was offline are handled as well.
+func ListenGuilds() {
+	Client.On(disgord.EvtGuildCreate, func(session disgord.Session, evt *disgord.GuildCreate) {
+		handleGuild(session, currentConfig(), evt.Guild)
+	})
+}
+
+// handleGuild leaves a guild or stays dormant in it if it is not on the guild allowlist.
+func handleGuild(session disgord.Session, cfg *common.Config, guild *disgord.Guild) {
+	if cfg.IsGuildAllowed(guild.ID) {
+		return
+	}
+
+	if cfg.DormantGuilds {
+		stayDormant(session, cfg, guild)
+	} else {
+		leaveGuild(session, cfg, guild)
+	}
+}
+
+// leaveUnlistedGuilds handles the joined guilds which the guild allowlist of cfg doesn't allow, e.g. after a reload.
+func leaveUnlistedGuilds(session disgord.Session, cfg *common.Config) {
+	for _, id := range session.GetConnectedGuilds() {
+		if cfg.IsGuildAllowed(id) {
+			continue
+		}
+
+		guild, err := session.GetGuild(id)
+		if err != nil {
+			common.Logger.Error(err)
+			continue
+		}
+		handleGuild(session, cfg, guild)
+	}
+}
+
+// stayDormant notifies the owners once about an unlisted guild, messages from it are ignored.
+func stayDormant(session disgord.Session, cfg *common.Config, guild *disgord.Guild) {
+	dormantGuildsMu.Lock()
+	notified := dormantGuilds[guild.ID]
+	dormantGuilds[guild.ID] = true
+	dormantGuildsMu.Unlock()
+	if notified {
+		return
+	}
+
+	common.Logger.Warn("Staying dormant in unlisted guild", guild.Name, guild.ID)
+	common.NotifyOwners(session, cfg.OwnerIDs, fmt.Sprintf(
+		"The bot was added to the server **%s** (%s, owner <@%s>), which is not on the guild allowlist. "+
+			"It stays in the server, but ignores it.",
+		guild.Name,
+		guild.ID,
+		guild.OwnerID,
+	))
+}
+
+// leaveGuild leaves an unlisted guild, deletes the reminders created in it and notifies the owners.
+func leaveGuild(session disgord.Session, cfg *common.Config, guild *disgord.Guild) {
+	common.Logger.Warn("Leaving unlisted guild", guild.Name, guild.ID)
+
+	err := session.LeaveGuild(guild.ID)
+	if err != nil {
+		common.Logger.Error(err)
+		common.NotifyOwners(session, cfg.OwnerIDs, fmt.Sprintf(
+			"The bot was added to the server **%s** (%s, owner <@%s>), which is not on the guild allowlist, "+
+				"but could not leave it: %s",
+			guild.Name,
+			guild.ID,
+			guild.OwnerID,
+			err.Error(),
+		))
+		return
+	}
+
+	deleted, err := common.DeleteGuildReminders(guild.ID)
+	if err != nil {
+		common.Logger.Error(err)
+	}
+
+	common.NotifyOwners(session, cfg.OwnerIDs, fmt.Sprintf(
+		"The bot was added to the server **%s** (%s, owner <@%s>), which is not on the guild allowlist, "+
+			"and left it. %d reminders created in it were deleted.",
+		guild.Name,
+		guild.ID,
+		guild.OwnerID,
+		deleted,
+	))
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/common"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+func TestHandleGuild(t *testing.T) {
+	const (
+		allowed  = disgord.Snowflake(500)
+		unlisted = disgord.Snowflake(501)
+	)
+	cfg := &common.Config{OwnerIDs: []disgord.Snowflake{testOwner}, GuildAllowlist: []disgord.Snowflake{allowed}}
+
+	reminder := &models.Reminder{UserID: testUser, GuildID: unlisted, Due: 1 << 40}
+	if err := common.DB.Create(reminder).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	session := &fakeSession{}
+	handleGuild(session, cfg, &disgord.Guild{ID: allowed, Name: "Team"})
+	if len(session.left) != 0 || len(session.messages()) != 0 {
+		t.Errorf("allowed guilds must be kept silently, left %v", session.left)
+	}
+
+	handleGuild(session, cfg, &disgord.Guild{ID: unlisted, Name: "Strangers", OwnerID: testUser})
+	if len(session.left) != 1 || session.left[0] != unlisted {
+		t.Errorf("left %v, want the unlisted guild", session.left)
+	}
+	if dms := session.dms(testOwner); len(dms) != 1 || !strings.Contains(dms[0], "**Strangers**") || !strings.Contains(dms[0], "1 reminders") {
+		t.Errorf("owners got %q", dms)
+	}
+	var count int
+	common.DB.Model(&models.Reminder{}).Where("guild_id = ?", unlisted).Count(&count)
+	if count != 0 {
+		t.Errorf("%d reminders of the left guild remain", count)
+	}
+}
+
+func TestHandleGuildDormant(t *testing.T) {
+	const unlisted = disgord.Snowflake(502)
+	cfg := &common.Config{OwnerIDs: []disgord.Snowflake{testOwner}, GuildAllowlist: []disgord.Snowflake{1}, DormantGuilds: true}
+
+	session := &fakeSession{}
+	for i := 0; i < 2; i++ {
+		handleGuild(session, cfg, &disgord.Guild{ID: unlisted, Name: "Strangers"})
+	}
+	if len(session.left) != 0 {
+		t.Errorf("dormant mode must not leave guilds, left %v", session.left)
+	}
+	if dms := session.dms(testOwner); len(dms) != 1 || !strings.Contains(dms[0], "ignores it") {
+		t.Errorf("owners must be notified once, got %q", dms)
+	}
+}
+
+func TestIsGuildAllowed(t *testing.T) {
+	cfg := &common.Config{GuildAllowlist: []disgord.Snowflake{500}}
+	if !cfg.IsGuildAllowed(500) || cfg.IsGuildAllowed(501) {
+		t.Error("only listed guilds are allowed")
+	}
+	if !cfg.IsGuildAllowed(0) {
+		t.Error("direct messages are always allowed")
+	}
+	if !(&common.Config{}).IsGuildAllowed(501) {
+		t.Error("every guild is allowed without allowlist")
+	}
+}
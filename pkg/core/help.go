@@ -0,0 +1,108 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/commands"
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+// Help lists the available commands by category or shows the usage of a single command.
+type Help struct{}
+
+func (*Help) Name() string {
+	return "help"
+}
+
+func (*Help) Aliases() []string {
+	return []string{}
+}
+
+func (*Help) Description() string {
+	return "List all available commands or show the usage of a command."
+}
+
+func (*Help) Category() common.CommandCategory {
+	return common.CategoryGeneral
+}
+
+func (*Help) Permission() common.PermissionLevel {
+	return common.PermissionDefault
+}
+
+func (*Help) Active() bool {
+	return true
+}
+
+// Execute sends a help message as embedded rich content to a channel.
+// With a command name, the help message of the addressed (sub)command is sent instead.
+func (*Help) Execute(s common.MessageState) {
+	if command, path, _ := Index.Resolve(strings.Fields(s.Args.String("command"))); command != nil {
+		s.SendEmbed(commands.HelpEmbed(command, strings.Join(path, " "), s.GuildPrefix()))
+		return
+	}
+
+	var fields []*disgord.EmbedField
+
+	// One field per category listing its commands.
+	for _, category := range common.CommandCategories {
+		var lines []string
+		for _, cmd := range commands.CommandList {
+			if cmd.Category() == category {
+				lines = append(lines, fmt.Sprintf("`%s` %s", cmd.Name(), cmd.Description()))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		// Categories with many commands are split into several fields, which are limited to 1024 characters.
+		var value []string
+		size := 0
+		for _, line := range lines {
+			if len(value) > 0 && size+len(line)+1 > 1024 {
+				fields = append(fields, &disgord.EmbedField{
+					Name:  string(category),
+					Value: strings.Join(value, "\n"),
+				})
+				value = nil
+				size = 0
+			}
+			value = append(value, line)
+			size += len(line) + 1
+		}
+		fields = append(fields, &disgord.EmbedField{
+			Name:  string(category),
+			Value: strings.Join(value, "\n"),
+		})
+	}
+
+	s.SendPaginated(&disgord.Embed{
+		Title: "Disgotify bot help message",
+		Description: fmt.Sprintf(
+			"This help message lists all available commands. Use `%shelp [command]` for the usage of a command.",
+			s.GuildPrefix(),
+		),
+		Color:  0xe5004c,
+		Fields: fields,
+	})
+}
+
+func (*Help) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Arguments: []common.Argument{
+			{
+				Name:        "command",
+				Description: "Name of a command or subcommand",
+				Type:        common.ArgumentText,
+				Optional:    true,
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Listing all available commands"},
+			{Description: "Showing the usage of a subcommand", Args: "reminder add"},
+		},
+	}
+}
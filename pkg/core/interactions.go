@@ -0,0 +1,453 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/common"
+	"golang.org/x/crypto/ed25519"
+)
+
+// InteractionType represents the type of a Discord interaction.
+type InteractionType uint
+
+// Discord interaction type.
+const (
+	InteractionPing               InteractionType = 1
+	InteractionApplicationCommand InteractionType = 2
+)
+
+// InteractionResponseType represents the type of a response to a Discord interaction.
+type InteractionResponseType uint
+
+// Discord interaction response type.
+const (
+	InteractionResponsePong                             InteractionResponseType = 1
+	InteractionResponseChannelMessageWithSource         InteractionResponseType = 4
+	InteractionResponseDeferredChannelMessageWithSource InteractionResponseType = 5
+)
+
+// Interaction represents an interaction webhook sent by Discord.
+type Interaction struct {
+	ID            disgord.Snowflake  `json:"id"`
+	ApplicationID disgord.Snowflake  `json:"application_id"`
+	Type          InteractionType    `json:"type"`
+	Data          *InteractionData   `json:"data"`
+	GuildID       disgord.Snowflake  `json:"guild_id"`
+	ChannelID     disgord.Snowflake  `json:"channel_id"`
+	Member        *InteractionMember `json:"member"`
+	User          *disgord.User      `json:"user"`
+	Token         string             `json:"token"`
+}
+
+// InteractionMember represents the guild member who invoked an interaction.
+type InteractionMember struct {
+	User *disgord.User `json:"user"`
+}
+
+// InteractionData represents the invoked slash command.
+type InteractionData struct {
+	ID      disgord.Snowflake   `json:"id"`
+	Name    string              `json:"name"`
+	Options []InteractionOption `json:"options"`
+}
+
+// InteractionOption represents an option value or a subcommand of the invoked slash command.
+type InteractionOption struct {
+	Name    string              `json:"name"`
+	Type    OptionType          `json:"type"`
+	Value   interface{}         `json:"value"`
+	Options []InteractionOption `json:"options"`
+}
+
+// InteractionResponse represents the response to an interaction.
+type InteractionResponse struct {
+	Type InteractionResponseType  `json:"type"`
+	Data *InteractionResponseData `json:"data,omitempty"`
+}
+
+// InteractionResponseData represents the message sent in response to an interaction.
+type InteractionResponseData struct {
+	Content         string                  `json:"content"`
+	Embeds          []*disgord.Embed        `json:"embeds,omitempty"`
+	AllowedMentions *common.AllowedMentions `json:"allowed_mentions,omitempty"`
+}
+
+const (
+	// maxInteractionContent is the maximum length of a message.
+	maxInteractionContent = 2000
+	// maxInteractionEmbeds is the maximum number of embeds in a message.
+	maxInteractionEmbeds = 10
+	// maxInteractionAge is the maximum difference between the signed timestamp of an interaction and now,
+	// older requests are rejected so they cannot be replayed.
+	maxInteractionAge = 5 * time.Minute
+)
+
+var (
+	interactionServer *http.Server
+	interactionKey    ed25519.PublicKey
+
+	// interactionAPI is the base URL of the webhook endpoints completing deferred interactions.
+	interactionAPI    = "https://discord.com/api/v8"
+	interactionClient = &http.Client{Timeout: 10 * time.Second}
+
+	// interactionBackend returns the session passing through everything but the messages to the interaction's channel.
+	interactionBackend = func() disgord.Session { return Client }
+)
+
+// StartInteractions starts an HTTP server receiving Discord interaction webhooks on /interactions.
+// publicKey is the hex encoded public key of the Discord application.
+func StartInteractions(addr, publicKey string) {
+	key, err := hex.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		common.Logger.Fatal("invalid Discord public key")
+	}
+	interactionKey = key
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/interactions", handleInteraction)
+	interactionServer = &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+
+	go func() {
+		err := interactionServer.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			common.Logger.Error(err)
+		}
+	}()
+}
+
+// StopInteractions gracefully stops the interaction server if it was started.
+func StopInteractions() {
+	if interactionServer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := interactionServer.Shutdown(ctx); err != nil {
+		common.Logger.Error(err)
+	}
+}
+
+// handleInteraction verifies and answers a single interaction webhook.
+func handleInteraction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "cannot read request", http.StatusBadRequest)
+		return
+	}
+
+	// Discord requires rejecting requests with invalid signatures.
+	if !VerifyInteraction(interactionKey, r.Header, body) {
+		http.Error(w, "invalid request signature", http.StatusUnauthorized)
+		return
+	}
+
+	// Numbers are kept as they were sent, float64 would format large integers in exponent notation.
+	var interaction Interaction
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&interaction); err != nil {
+		http.Error(w, "invalid interaction", http.StatusBadRequest)
+		return
+	}
+
+	var response *InteractionResponse
+	var job func()
+	switch interaction.Type {
+	case InteractionPing:
+		response = &InteractionResponse{Type: InteractionResponsePong}
+	case InteractionApplicationCommand:
+		response, job = executeInteraction(&interaction)
+	default:
+		http.Error(w, "unsupported interaction type", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		common.Logger.Error(err)
+	}
+	if job == nil {
+		return
+	}
+
+	// The deferred response has to reach Discord before the command completes it.
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	if !common.CommandQueue.Submit(interactionUser(&interaction).ID, job) {
+		common.Logger.Warn("Command queue is full, turning away a slash command of", interactionUser(&interaction).ID)
+		completeInteraction(&interaction, interactionMessage("I'm busy right now, please try again in a moment.").Data)
+	}
+}
+
+// VerifyInteraction returns a bool which indicates whether the request was signed by the owner of the key.
+// The signature covers the timestamp header followed by the body.
+func VerifyInteraction(key ed25519.PublicKey, header http.Header, body []byte) bool {
+	signature, err := hex.DecodeString(header.Get("X-Signature-Ed25519"))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return false
+	}
+
+	timestamp := header.Get("X-Signature-Timestamp")
+	if timestamp == "" || len(key) != ed25519.PublicKeySize {
+		return false
+	}
+
+	// Signed requests are only accepted for a while, so they cannot be replayed later.
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > maxInteractionAge || age < -maxInteractionAge {
+		return false
+	}
+
+	return ed25519.Verify(key, append([]byte(timestamp), body...), signature)
+}
+
+// executeInteraction maps a slash command onto the registered command. Invalid interactions are answered right away,
+// otherwise a deferred response is returned with the job running the command through the middleware chain.
+// Everything the command sends to the interaction's channel completes the deferred response.
+func executeInteraction(interaction *Interaction) (*InteractionResponse, func()) {
+	if interaction.Data == nil {
+		return interactionMessage("Unknown command."), nil
+	}
+
+	// Subcommands (and groups) are nested options, which only consist of themselves.
+	words := []string{interaction.Data.Name}
+	options := interaction.Data.Options
+	for len(options) == 1 && (options[0].Type == OptionSubCommand || options[0].Type == OptionSubCommandGroup) {
+		words = append(words, options[0].Name)
+		options = options[0].Options
+	}
+
+	command, path, _ := Index.Resolve(words)
+	if command == nil || len(path) != len(words) {
+		return interactionMessage("Unknown command."), nil
+	}
+
+	cfg := currentConfig()
+	values := map[string]string{}
+	content := []string{common.GuildPrefix(interaction.GuildID, cfg.CommandPrefix) + strings.Join(path, " ")}
+	for _, option := range options {
+		value := fmt.Sprint(option.Value)
+		values[option.Name] = value
+		content = append(content, value)
+	}
+
+	user := interactionUser(interaction)
+	if user == nil {
+		return interactionMessage("Unknown user."), nil
+	}
+	// Interactions have to be answered, even for blocked users.
+	if common.IsBlocked(user.ID) {
+		return interactionMessage("You cannot use this bot."), nil
+	}
+	if !cfg.IsGuildAllowed(interaction.GuildID) {
+		return interactionMessage("This bot cannot be used in this server."), nil
+	}
+
+	session := &interactionSession{
+		Session:   interactionBackend(),
+		channelID: interaction.ChannelID,
+		isDM:      interaction.GuildID.Empty(),
+	}
+
+	inv := &Invocation{
+		State: common.MessageState{
+			Session: session,
+			Event: &disgord.MessageCreate{
+				Message: &disgord.Message{
+					ID:        interaction.ID,
+					ChannelID: interaction.ChannelID,
+					GuildID:   interaction.GuildID,
+					Author:    user,
+					Content:   strings.Join(content, " "),
+				},
+			},
+			Config: cfg,
+		},
+		Command: command,
+		Path:    path,
+		Options: values,
+	}
+
+	return &InteractionResponse{Type: InteractionResponseDeferredChannelMessageWithSource}, func() {
+		dispatch(inv)
+		completeInteraction(interaction, session.response().Data)
+	}
+}
+
+// interactionUser returns the user who invoked an interaction, nil if it has none.
+func interactionUser(interaction *Interaction) *disgord.User {
+	if interaction.Member != nil && interaction.Member.User != nil {
+		return interaction.Member.User
+	}
+	return interaction.User
+}
+
+// completeInteraction replaces the deferred response of an interaction with the message.
+func completeInteraction(interaction *Interaction, data *InteractionResponseData) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		common.Logger.Error(err)
+		return
+	}
+
+	url := fmt.Sprintf("%s/webhooks/%s/%s/messages/@original", interactionAPI, interaction.ApplicationID, interaction.Token)
+	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(body))
+	if err != nil {
+		common.Logger.Error(err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := interactionClient.Do(req)
+	if err != nil {
+		common.Logger.Error(err)
+		return
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		message, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1<<10))
+		common.Logger.Error(fmt.Sprintf("Cannot complete interaction %s: %s %s", interaction.ID, res.Status, message))
+	}
+}
+
+// interactionMessage returns a response with a plain message.
+func interactionMessage(content string) *InteractionResponse {
+	return &InteractionResponse{
+		Type: InteractionResponseChannelMessageWithSource,
+		Data: &InteractionResponseData{
+			Content: content,
+		},
+	}
+}
+
+// interactionSession represents a session capturing all messages sent to the interaction's channel.
+// Everything else is passed through to the actual session.
+type interactionSession struct {
+	disgord.Session
+	channelID disgord.Snowflake
+	isDM      bool
+
+	mu       sync.Mutex
+	contents []string
+	embeds   []*disgord.Embed
+	// mentions restricts the mentions of the response once a message restricted them.
+	mentions *common.AllowedMentions
+}
+
+// SendMsg captures messages to the interaction's channel.
+func (s *interactionSession) SendMsg(channelID disgord.Snowflake, data ...interface{}) (*disgord.Message, error) {
+	if channelID != s.channelID {
+		return s.Session.SendMsg(channelID, data...)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var content []string
+	for _, d := range data {
+		switch t := d.(type) {
+		case nil:
+		case *disgord.CreateMessageParams:
+			content = append(content, t.Content)
+			if t.Embed != nil {
+				s.embeds = append(s.embeds, t.Embed)
+			}
+		case disgord.CreateMessageParams:
+			content = append(content, t.Content)
+			if t.Embed != nil {
+				s.embeds = append(s.embeds, t.Embed)
+			}
+		default:
+			content = append(content, fmt.Sprint(t))
+		}
+	}
+	if c := strings.TrimSpace(strings.Join(content, " ")); c != "" {
+		s.contents = append(s.contents, c)
+	}
+
+	return &disgord.Message{
+		ChannelID: channelID,
+		Content:   strings.Join(content, " "),
+	}, nil
+}
+
+// SendRestrictedMsg captures messages with restricted mentions to the interaction's channel.
+func (s *interactionSession) SendRestrictedMsg(channelID disgord.Snowflake, content string, mentions *common.AllowedMentions) (*disgord.Message, error) {
+	if channelID != s.channelID {
+		return common.SendRestricted(s.Session, channelID, content)
+	}
+
+	s.mu.Lock()
+	s.mentions = mentions
+	s.mu.Unlock()
+
+	return s.SendMsg(channelID, content)
+}
+
+// GetChannel answers requests for the interaction's channel without a REST request.
+func (s *interactionSession) GetChannel(id disgord.Snowflake, flags ...disgord.Flag) (*disgord.Channel, error) {
+	if id != s.channelID {
+		return s.Session.GetChannel(id, flags...)
+	}
+
+	channelType := disgord.ChannelTypeGuildText
+	if s.isDM {
+		channelType = disgord.ChannelTypeDM
+	}
+	return &disgord.Channel{
+		ID:   id,
+		Type: channelType,
+	}, nil
+}
+
+// response returns the captured messages as interaction response.
+func (s *interactionSession) response() *InteractionResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// All replies are sent as a single message, so they are shortened to fit.
+	content := common.Truncate(strings.Join(s.contents, "\n"), maxInteractionContent)
+	if content == "" && len(s.embeds) == 0 {
+		content = "Done."
+	}
+
+	embeds := s.embeds
+	if len(embeds) > maxInteractionEmbeds {
+		embeds = embeds[:maxInteractionEmbeds]
+	}
+
+	return &InteractionResponse{
+		Type: InteractionResponseChannelMessageWithSource,
+		Data: &InteractionResponseData{
+			Content:         content,
+			Embeds:          embeds,
+			AllowedMentions: s.mentions,
+		},
+	}
+}
@@ -0,0 +1,247 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/commands"
+	"github.com/qysp/disgotify/pkg/common"
+	"golang.org/x/crypto/ed25519"
+)
+
+// signInteraction returns a request with the body signed like Discord does.
+func signInteraction(t *testing.T, key ed25519.PrivateKey, body []byte, signedAt time.Time) *http.Request {
+	t.Helper()
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	signature := ed25519.Sign(key, append([]byte(timestamp), body...))
+
+	req := httptest.NewRequest(http.MethodPost, "/interactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature-Ed25519", hex.EncodeToString(signature))
+	req.Header.Set("X-Signature-Timestamp", timestamp)
+	return req
+}
+
+// readFixture returns an interaction fixture of the testdata directory.
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := ioutil.ReadFile(filepath.Join("..", "..", "testdata", "interactions", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func generateKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return public, private
+}
+
+func TestVerifyInteraction(t *testing.T) {
+	public, private := generateKey(t)
+	otherPublic, _ := generateKey(t)
+	body := readFixture(t, "ping.json")
+
+	tests := []struct {
+		name  string
+		key   ed25519.PublicKey
+		req   *http.Request
+		body  []byte
+		valid bool
+	}{
+		{"valid", public, signInteraction(t, private, body, time.Now()), body, true},
+		{"tampered body", public, signInteraction(t, private, body, time.Now()), append(body, ' '), false},
+		{"other key", otherPublic, signInteraction(t, private, body, time.Now()), body, false},
+		{"missing key", nil, signInteraction(t, private, body, time.Now()), body, false},
+		{"stale timestamp", public, signInteraction(t, private, body, time.Now().Add(-10*time.Minute)), body, false},
+		{"future timestamp", public, signInteraction(t, private, body, time.Now().Add(10*time.Minute)), body, false},
+	}
+
+	for _, test := range tests {
+		if got := VerifyInteraction(test.key, test.req.Header, test.body); got != test.valid {
+			t.Errorf("%s: VerifyInteraction = %v, want %v", test.name, got, test.valid)
+		}
+	}
+
+	header := signInteraction(t, private, body, time.Now()).Header
+	header.Set("X-Signature-Ed25519", "not hex")
+	if VerifyInteraction(public, header, body) {
+		t.Error("a malformed signature must be rejected")
+	}
+	header = signInteraction(t, private, body, time.Now()).Header
+	header.Set("X-Signature-Timestamp", "yesterday")
+	if VerifyInteraction(public, header, body) {
+		t.Error("a malformed timestamp must be rejected")
+	}
+}
+
+// interactionTest serves interactions with a test key and records the completed deferred responses.
+type interactionTest struct {
+	private   ed25519.PrivateKey
+	completed chan completion
+}
+
+// completion represents a request completing a deferred response.
+type completion struct {
+	path string
+	data InteractionResponseData
+}
+
+func newInteractionTest(t *testing.T) (*interactionTest, func()) {
+	public, private := generateKey(t)
+	test := &interactionTest{private: private, completed: make(chan completion, 10)}
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var c completion
+		c.path = r.Method + " " + r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&c.data); err != nil {
+			t.Error(err)
+		}
+		test.completed <- c
+	}))
+
+	previousKey, previousAPI, previousBackend, previousQueue := interactionKey, interactionAPI, interactionBackend, common.CommandQueue
+	interactionKey, interactionAPI, common.CommandQueue = public, api.URL, common.NewWorkerPool(1, 10)
+	interactionBackend = func() disgord.Session { return &fakeSession{} }
+
+	return test, func() {
+		common.CommandQueue.Stop()
+		api.Close()
+		interactionKey, interactionAPI, interactionBackend, common.CommandQueue = previousKey, previousAPI, previousBackend, previousQueue
+	}
+}
+
+// send signs and sends an interaction and returns the immediate response.
+func (it *interactionTest) send(t *testing.T, body []byte) (int, InteractionResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handleInteraction(rec, signInteraction(t, it.private, body, time.Now()))
+
+	var response InteractionResponse
+	if rec.Code == http.StatusOK {
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rec.Code, response
+}
+
+// completion waits for the completion of a deferred response.
+func (it *interactionTest) completion(t *testing.T) completion {
+	t.Helper()
+	select {
+	case c := <-it.completed:
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("the deferred response was not completed")
+	}
+	return completion{}
+}
+
+func TestHandleInteraction(t *testing.T) {
+	it, done := newInteractionTest(t)
+	defer done()
+
+	var index int64
+	defer useTestIndex(
+		&testCommand{name: "ping", execute: func(s common.MessageState) { s.Reply("Pong!") }},
+		commands.NewGroup("reminder", nil, "", common.CategoryReminders, &testCommand{
+			name:      "remove",
+			arguments: []common.Argument{{Name: "index", Type: common.ArgumentInteger}},
+			execute: func(s common.MessageState) {
+				index = s.Args.Int("index")
+				s.Reply("Removed.")
+			},
+		}),
+	)()
+
+	if code, response := it.send(t, readFixture(t, "ping.json")); code != http.StatusOK || response.Type != InteractionResponsePong {
+		t.Errorf("a ping must be answered with a pong, got %d %+v", code, response)
+	}
+
+	code, response := it.send(t, readFixture(t, "ping-command.json"))
+	if code != http.StatusOK || response.Type != InteractionResponseDeferredChannelMessageWithSource {
+		t.Fatalf("a command must be deferred, got %d %+v", code, response)
+	}
+	c := it.completion(t)
+	if c.path != "PATCH /webhooks/771825006014889980/fixture/messages/@original" || c.data.Content != "<@53908232506183680> Pong!" {
+		t.Errorf("got completion %+v", c)
+	}
+
+	// Subcommand of a DM, the user isn't mentioned.
+	it.send(t, readFixture(t, "reminder-remove.json"))
+	if c := it.completion(t); c.data.Content != "Removed." || index != 1 {
+		t.Errorf("got completion %+v and index %d", c, index)
+	}
+
+	// Large integers are passed as they were sent.
+	body := bytes.Replace(readFixture(t, "reminder-remove.json"), []byte(`"value": 1 `), []byte(`"value": 1000000 `), 1)
+	it.send(t, body)
+	if c := it.completion(t); c.data.Content != "Removed." || index != 1000000 {
+		t.Errorf("got completion %+v and index %d", c, index)
+	}
+
+	// Unknown commands are answered right away.
+	body = bytes.Replace(readFixture(t, "ping-command.json"), []byte(`"name": "ping"`), []byte(`"name": "pong"`), 1)
+	if _, response := it.send(t, body); response.Type != InteractionResponseChannelMessageWithSource || response.Data.Content != "Unknown command." {
+		t.Errorf("got %+v", response)
+	}
+}
+
+func TestHandleInteractionRejected(t *testing.T) {
+	it, done := newInteractionTest(t)
+	defer done()
+	body := readFixture(t, "ping.json")
+
+	rec := httptest.NewRecorder()
+	req := signInteraction(t, it.private, body, time.Now())
+	req.Body = ioutil.NopCloser(bytes.NewReader(append(body, ' ')))
+	handleInteraction(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("a tampered request must be rejected, got %d", rec.Code)
+	}
+
+	_, other := generateKey(t)
+	rec = httptest.NewRecorder()
+	handleInteraction(rec, signInteraction(t, other, body, time.Now()))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("a request signed with another key must be rejected, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handleInteraction(rec, httptest.NewRequest(http.MethodGet, "/interactions", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("only POST requests are accepted, got %d", rec.Code)
+	}
+}
+
+func TestInteractionSessionResponse(t *testing.T) {
+	session := &interactionSession{channelID: testChannel}
+	if data := session.response().Data; data.Content != "Done." {
+		t.Errorf("got %q for no replies, want Done.", data.Content)
+	}
+
+	for i := 0; i < 3; i++ {
+		session.SendMsg(testChannel, strings.Repeat("a", 1000))
+	}
+	content := session.response().Data.Content
+	if utf8.RuneCountInString(content) > maxInteractionContent || !strings.HasSuffix(content, "…") {
+		t.Errorf("the replies must be shortened to %d characters, got %d", maxInteractionContent, utf8.RuneCountInString(content))
+	}
+}
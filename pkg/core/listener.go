@@ -1,6 +1,7 @@
 package core
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/andersfylling/disgord"
@@ -8,62 +9,144 @@ import (
 	"github.com/qysp/disgotify/pkg/common"
 )
 
-// ListenMessages listens for Discord messages.
+// ListenMessages listens for Discord messages and queues the ones which seem relevant.
 func ListenMessages() {
 	Client.On(disgord.EvtMessageCreate, func(session disgord.Session, evt *disgord.MessageCreate) {
 		s := common.MessageState{
 			Session: session,
 			Event:   evt,
+			Config:  currentConfig(),
 		}
 
 		// Prefix is always needed, except in a direct message.
-		if !s.IsDMChannel() && !s.HasPrefix() || s.IsBot() {
+		if s.IsBot() || !s.HasPrefix() && !s.IsDMChannel() {
 			return
 		}
 
-		userCmd := strings.ToLower(s.UserCommand())
-
-		// Global help message.
-		if userCmd == "help" {
-			sendHelpMessage(s)
+		// Blocked users and guilds which are not allowed are ignored entirely.
+		if common.IsBlocked(s.UserID()) || !s.Config.IsGuildAllowed(s.GuildID()) {
 			return
 		}
 
-		command := Index.Get(userCmd)
+		// Commands of the same user are handled in order by the same worker.
+		queued := common.CommandQueue.Submit(s.UserID(), func() {
+			handleMessage(s)
+		})
+		if !queued {
+			common.Logger.Warn("Command queue is full, turning away a command of", s.UserID())
+			s.Reply("I'm busy right now, please try again in a moment.")
+		}
+	})
+}
+
+// handleMessage resolves the command of a message and dispatches it.
+func handleMessage(s common.MessageState) {
+	// A bare mention asks how to use the bot.
+	if s.IsMentionPrefix() && s.UserCommand() == "" && len(s.UserCommandArgs()) == 0 {
+		s.Reply(fmt.Sprintf(
+			"My prefix is `%s`, use `%shelp` for a list of commands.",
+			s.GuildPrefix(),
+			s.GuildPrefix(),
+		))
+		return
+	}
+
+	words := expandAliases(s, append([]string{s.UserCommand()}, s.UserCommandArgs()...))
+	command, path, args := Index.Resolve(words)
 
-		if command == nil {
+	if command == nil {
+		// Custom commands of the guild are resolved after the built-in commands.
+		if custom := findCustomCommand(s, words[0]); custom != nil {
+			dispatch(&Invocation{
+				State:   s,
+				Command: custom,
+				Path:    []string{custom.Name()},
+				Args:    words[1:],
+			})
 			return
 		}
 
-		if command.Permission() > s.UserPermission() {
-			s.Reply("You don't have permissions to use this command!")
-		}
+		suggestCommand(s, words[0])
+		return
+	}
 
-		command.Execute(s)
+	dispatch(&Invocation{
+		State:   s,
+		Command: command,
+		Path:    path,
+		Args:    args,
 	})
 }
 
-// sendHelpMessage sends a help message as embedded rich content to a channel.
-func sendHelpMessage(s common.MessageState) {
-	if len(s.UserCommandArgs()) > 0 && Index.Has(s.UserCommandArgs()[0]) {
-		command := Index.Get(s.UserCommandArgs()[0])
-		command.Help(s)
+// ListenReactions listens for reactions turning the pages of paginated messages.
+func ListenReactions() {
+	Client.On(disgord.EvtMessageReactionAdd, func(session disgord.Session, evt *disgord.MessageReactionAdd) {
+		common.HandlePageReaction(session, evt.ChannelID, evt.MessageID, evt.UserID, evt.PartialEmoji)
+	})
+	Client.On(disgord.EvtMessageReactionRemove, func(session disgord.Session, evt *disgord.MessageReactionRemove) {
+		common.HandlePageReaction(session, evt.ChannelID, evt.MessageID, evt.UserID, evt.PartialEmoji)
+	})
+}
+
+// suggestCommand replies with the most similar command to an unknown one, unless the guild disabled it.
+// Only commands the user may use are suggested. Messages without prefix (in a DM) are most likely
+// not meant as commands and are ignored.
+func suggestCommand(s common.MessageState, userCmd string) {
+	if userCmd == "" || !s.HasPrefix() {
 		return
 	}
 
-	var fields []*disgord.EmbedField
+	if !s.GuildID().Empty() {
+		settings, err := common.GetGuildSettings(s.GuildID())
+		if err != nil {
+			common.Logger.Error(err)
+			return
+		}
+		if settings.DisableSuggestions {
+			return
+		}
+	}
 
+	usable, err := usableCommands(s)
+	if err != nil {
+		common.Logger.Error(err)
+		return
+	}
+	suggestion, ok := Index.Suggest(strings.ToLower(userCmd), usable)
+	if !ok {
+		return
+	}
+
+	s.Reply(fmt.Sprintf("Unknown command `%s`, did you mean `%s`?", userCmd, suggestion))
+}
+
+// usableCommands returns a function which indicates whether the user's permission level
+// and the command rules of the guild let them use a command in the channel.
+func usableCommands(s common.MessageState) (func(commands.Command) bool, error) {
+	level := s.UserPermission()
+	if level >= common.PermissionDeveloper || s.GuildID().Empty() {
+		return func(cmd commands.Command) bool {
+			return cmd.Permission() <= level
+		}, nil
+	}
+
+	rules, err := common.GetCommandRules(s.GuildID())
+	if err != nil {
+		return nil, err
+	}
+	var names []string
 	for _, cmd := range commands.CommandList {
-		fields = append(fields, &disgord.EmbedField{
-			Name:  cmd.Name(),
-			Value: cmd.Description(),
-		})
+		names = append(names, cmd.Name())
+	}
+	subject, err := ruleSubject(s, rules, names, s.UserID(), s.Event.Message.ChannelID)
+	if err != nil {
+		return nil, err
 	}
 
-	s.SendEmbed(&disgord.Embed{
-		Title:       "Disgotify bot help message",
-		Description: "This help message lists all available commands.",
-		Color:       0xe5004c,
-		Fields:      fields,
-	})
+	return func(cmd commands.Command) bool {
+		if cmd.Permission() > level {
+			return false
+		}
+		return isProtectedCommand(cmd.Name()) || common.EvaluateCommandRules(rules, []string{cmd.Name()}, subject).Allowed
+	}, nil
 }
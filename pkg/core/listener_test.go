@@ -0,0 +1,60 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/andersfylling/disgord"
+
+	"github.com/qysp/disgotify/pkg/common"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+func TestSuggestCommand(t *testing.T) {
+	defer useTestIndex(
+		&testCommand{name: "remind"},
+		&testCommand{name: "stats", permission: common.PermissionDeveloper},
+	)()
+
+	suggest := func(userCmd string) []string {
+		session := &fakeSession{}
+		suggestCommand(newTestState(session, testUser, "+"+userCmd), userCmd)
+		return session.messages()
+	}
+
+	if messages := suggest("remnd"); len(messages) != 1 || messages[0] != "<@2> Unknown command `remnd`, did you mean `remind`?" {
+		t.Errorf("got %q", messages)
+	}
+	if messages := suggest("stat"); len(messages) != 0 {
+		t.Errorf("commands requiring a higher permission level must not be suggested, got %q", messages)
+	}
+
+	rule := &models.CommandRule{GuildID: testGuild, Command: "remind", TargetType: models.RuleTargetUser, TargetID: testUser}
+	if err := common.SetCommandRule(rule); err != nil {
+		t.Fatal(err)
+	}
+	defer common.DeleteCommandRule(testGuild, rule.ID)
+	if messages := suggest("remnd"); len(messages) != 0 {
+		t.Errorf("commands denied by a rule must not be suggested, got %q", messages)
+	}
+}
+
+func TestHandleMessageMention(t *testing.T) {
+	defer func(id disgord.Snowflake) { common.BotID = id }(common.BotID)
+	common.BotID = 42
+
+	ping := &testCommand{name: "ping"}
+	defer useTestIndex(ping)()
+
+	session := &fakeSession{}
+	handleMessage(newTestState(session, testUser, "<@42>"))
+	if messages := session.messages(); len(messages) != 1 || messages[0] != "<@2> My prefix is `+`, use `+help` for a list of commands." {
+		t.Errorf("bare mention got %q", messages)
+	}
+
+	for _, content := range []string{"<@42> ping", "<@!42> ping", "+ping"} {
+		handleMessage(newTestState(&fakeSession{}, testUser, content))
+	}
+	if n := ping.executions(); n != 3 {
+		t.Errorf("ping executed %d times, want 3", n)
+	}
+}
@@ -0,0 +1,230 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qysp/disgotify/pkg/commands"
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+// Invocation represents a single command invocation passed through the middleware chain.
+type Invocation struct {
+	State   common.MessageState
+	Command commands.Command
+	// Path consists of the command names, e.g. ["reminder", "add"].
+	Path []string
+	// Args are the raw argument words following the command path.
+	Args []string
+	// Options are named argument values (of slash commands), they replace Args if not nil.
+	Options map[string]string
+
+	// executed is set once the command's Execute was called, failed if it panicked.
+	executed bool
+	failed   bool
+	// timedOut is set if the command didn't finish in time, the other flags must not be read then.
+	timedOut bool
+}
+
+// Name returns the full name of the invoked command, e.g. "reminder add".
+func (inv *Invocation) Name() string {
+	return strings.Join(inv.Path, " ")
+}
+
+// Handler represents a function handling a command invocation.
+type Handler func(inv *Invocation)
+
+// Middleware represents a function wrapping a handler, it decides whether and when to call next.
+type Middleware func(next Handler) Handler
+
+// middlewares represents the ordered middleware chain, the first middleware is the outermost.
+var middlewares = []Middleware{
+	StatsMiddleware,
+	TimeoutMiddleware,
+	RecoveryMiddleware,
+	LoggingMiddleware,
+	MaintenanceMiddleware,
+	GuildCommandMiddleware,
+	ACLMiddleware,
+	PermissionMiddleware,
+	// Invocations with invalid arguments count against the cooldowns too, they are answered with the usage.
+	CooldownMiddleware,
+	ArgumentMiddleware,
+}
+
+// Use appends middlewares to the chain, they run after the built-in middlewares and right before Execute.
+func Use(mw ...Middleware) {
+	middlewares = append(middlewares, mw...)
+}
+
+// dispatch passes an invocation through the middleware chain and finally executes the command.
+func dispatch(inv *Invocation) {
+	handler := func(inv *Invocation) {
+		inv.executed = true
+		inv.Command.Execute(inv.State)
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	handler(inv)
+}
+
+// StatsMiddleware records every invocation and its outcome in the command statistics.
+// Commands which panicked, timed out or replied with an unexpected error count as failed.
+func StatsMiddleware(next Handler) Handler {
+	return func(inv *Invocation) {
+		// The state may still be changed by a timed out command.
+		use := common.CommandUse{
+			Command: inv.Name(),
+			GuildID: inv.State.GuildID(),
+			UserID:  inv.State.UserID(),
+			DM:      inv.State.GuildID().Empty(),
+		}
+
+		inv.State.Outcome = &common.Outcome{}
+
+		start := time.Now()
+		next(inv)
+
+		use.Failed = inv.timedOut || inv.failed || inv.State.Outcome.Err() != nil
+		use.Rejected = !inv.timedOut && !inv.executed
+		use.Duration = time.Since(start)
+		if err := common.RecordCommand(use); err != nil {
+			common.Logger.Error(err)
+		}
+	}
+}
+
+// TimeoutMiddleware cancels the context of invocations which don't finish before their deadline
+// or when the bot shuts down, and tells the user instead of waiting for the command.
+func TimeoutMiddleware(next Handler) Handler {
+	return func(inv *Invocation) {
+		// The state without context is used to reply once the command's context is done.
+		state := inv.State
+
+		ctx, cancel := context.WithTimeout(rootContext, commandTimeout(inv.State.Config, inv.Command))
+		defer cancel()
+		inv.State.Ctx = ctx
+
+		done := make(chan struct{})
+		runningCommands.Add(1)
+		go func() {
+			defer runningCommands.Done()
+			defer close(done)
+			next(inv)
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			inv.timedOut = true
+			if ctx.Err() == context.DeadlineExceeded {
+				state.Reply("Sorry, the command timed out!")
+			} else {
+				state.Reply("Sorry, the bot is shutting down!")
+			}
+		}
+	}
+}
+
+// runningCommands counts the executing commands including the ones TimeoutMiddleware stopped waiting for,
+// the database is only closed once they are done.
+var runningCommands sync.WaitGroup
+
+// commandTimeout returns the configured timeout of a command, falling back to the declared one and the default.
+func commandTimeout(cfg *common.Config, cmd commands.Command) time.Duration {
+	cmd = commands.Unwrap(cmd)
+	if timeout, ok := cfg.CommandTimeouts[cmd.Name()]; ok {
+		return timeout
+	}
+	if c, ok := cmd.(commands.TimeoutCommand); ok {
+		return c.Timeout()
+	}
+	return cfg.CommandTimeout
+}
+
+// RecoveryMiddleware recovers panics of the following handlers and reports them.
+func RecoveryMiddleware(next Handler) Handler {
+	return func(inv *Invocation) {
+		defer func() {
+			cause := recover()
+			if cause == nil {
+				return
+			}
+
+			inv.failed = true
+			origin := fmt.Sprintf("command \"%s\" (message: %s)", inv.Name(), inv.State.Message())
+			ref := common.ReportPanic(inv.State.Session, inv.State.Config.OwnerIDs, cause, origin)
+			inv.State.Reply(fmt.Sprintf("Something went wrong (ref %s).", ref))
+		}()
+		next(inv)
+	}
+}
+
+// LoggingMiddleware logs every invocation and how long it took.
+func LoggingMiddleware(next Handler) Handler {
+	return func(inv *Invocation) {
+		start := time.Now()
+		next(inv)
+		common.Logger.Debug(fmt.Sprintf(
+			"Command \"%s\" invoked by %s took %s",
+			inv.Name(),
+			inv.State.UserID(),
+			time.Since(start),
+		))
+	}
+}
+
+// MaintenanceMiddleware only lets developers use commands while the bot is in maintenance mode.
+func MaintenanceMiddleware(next Handler) Handler {
+	return func(inv *Invocation) {
+		if inv.State.Config.Maintenance && inv.State.UserPermission() < common.PermissionDeveloper {
+			inv.State.Reply("The bot is currently under maintenance, please try again later.")
+			return
+		}
+		next(inv)
+	}
+}
+
+// PermissionMiddleware stops invocations of commands requiring a higher permission level than the user's.
+func PermissionMiddleware(next Handler) Handler {
+	return func(inv *Invocation) {
+		if inv.Command.Permission() > inv.State.UserPermission() {
+			inv.State.Reply("You don't have permissions to use this command!")
+			return
+		}
+		next(inv)
+	}
+}
+
+// ArgumentMiddleware parses the arguments according to the command's usage into MessageState.Args.
+func ArgumentMiddleware(next Handler) Handler {
+	return func(inv *Invocation) {
+		var args common.Arguments
+		var err error
+		if inv.Options != nil {
+			args, err = common.ParseOptions(inv.Command.Usage().Arguments, inv.Options)
+		} else {
+			args, err = common.ParseArguments(inv.Command.Usage().Arguments, inv.Args)
+		}
+		if err != nil {
+			// Without any arguments the user most likely wants to know how to use the command.
+			if err.(*common.ArgumentError).Missing {
+				inv.State.SendEmbed(commands.HelpEmbed(inv.Command, inv.Name(), inv.State.GuildPrefix()))
+				return
+			}
+			inv.State.Reply(fmt.Sprintf(
+				"Sorry, %s! Use `%shelp %s` for usage.",
+				err.Error(),
+				inv.State.GuildPrefix(),
+				inv.Name(),
+			))
+			return
+		}
+		inv.State.Args = args
+		next(inv)
+	}
+}
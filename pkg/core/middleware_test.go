@@ -0,0 +1,133 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qysp/disgotify/pkg/common"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+func TestDispatchOrder(t *testing.T) {
+	defer func(chain []Middleware) { middlewares = chain }(middlewares)
+
+	var calls []string
+	record := func(name string, stop bool) Middleware {
+		return func(next Handler) Handler {
+			return func(inv *Invocation) {
+				calls = append(calls, name)
+				if !stop {
+					next(inv)
+				}
+			}
+		}
+	}
+
+	cmd := &testCommand{name: "test"}
+	middlewares = []Middleware{record("first", false), record("second", false)}
+	Use(record("third", false))
+	dispatch(&Invocation{Command: cmd, Path: []string{"test"}})
+
+	if strings.Join(calls, ",") != "first,second,third" || cmd.executions() != 1 {
+		t.Errorf("got calls %q and %d executions", calls, cmd.executions())
+	}
+
+	calls = nil
+	middlewares = []Middleware{record("first", true), record("second", false)}
+	dispatch(&Invocation{Command: cmd, Path: []string{"test"}})
+	if strings.Join(calls, ",") != "first" || cmd.executions() != 1 {
+		t.Errorf("a middleware not calling next must stop the chain, got calls %q", calls)
+	}
+}
+
+func TestPermissionMiddleware(t *testing.T) {
+	session := &fakeSession{}
+	cmd := &testCommand{name: "secret", permission: common.PermissionDeveloper}
+
+	dispatch(&Invocation{State: newTestState(session, testUser, "+secret"), Command: cmd, Path: []string{"secret"}})
+	if cmd.executions() != 0 || !strings.Contains(strings.Join(session.messages(), "\n"), "You don't have permissions") {
+		t.Errorf("a user must not run a developer command, got %q", session.messages())
+	}
+
+	dispatch(&Invocation{State: newTestState(session, testOwner, "+secret"), Command: cmd, Path: []string{"secret"}})
+	if cmd.executions() != 1 {
+		t.Error("an owner must run a developer command")
+	}
+}
+
+func TestArgumentMiddleware(t *testing.T) {
+	session := &fakeSession{}
+	var count int64
+	cmd := &testCommand{
+		name:      "count",
+		arguments: []common.Argument{{Name: "count", Type: common.ArgumentInteger}},
+		execute: func(s common.MessageState) {
+			count = s.Args.Int("count")
+		},
+	}
+
+	dispatch(&Invocation{State: newTestState(session, testUser, "+count ten"), Command: cmd, Path: []string{"count"}, Args: []string{"ten"}})
+	if cmd.executions() != 0 || !strings.Contains(strings.Join(session.messages(), "\n"), "\"ten\" is not a number") {
+		t.Errorf("invalid arguments must be rejected, got %q", session.messages())
+	}
+
+	dispatch(&Invocation{State: newTestState(session, testUser, "+count"), Command: cmd, Path: []string{"count"}})
+	if messages := session.messages(); cmd.executions() != 0 || messages[len(messages)-1] != "Command \"count\" usage" {
+		t.Errorf("missing arguments must show the usage, got %q", messages)
+	}
+
+	dispatch(&Invocation{State: newTestState(session, testUser, "+count 10"), Command: cmd, Path: []string{"count"}, Args: []string{"10"}})
+	if cmd.executions() != 1 || count != 10 {
+		t.Errorf("valid arguments must be parsed, got %d", count)
+	}
+}
+
+func TestStatsMiddleware(t *testing.T) {
+	stats := func(name string) models.CommandStat {
+		all, err := common.GetCommandStats(context.Background(), time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, stat := range all {
+			if stat.Command == name {
+				return stat
+			}
+		}
+		return models.CommandStat{}
+	}
+
+	ok := &testCommand{name: "stats-ok"}
+	failing := &testCommand{name: "stats-fail", execute: func(s common.MessageState) {
+		s.Fail(errors.New("database is gone"))
+	}}
+	denied := &testCommand{name: "stats-denied", permission: common.PermissionDeveloper}
+
+	for _, cmd := range []*testCommand{ok, failing, denied} {
+		session := &fakeSession{}
+		dispatch(&Invocation{State: newTestState(session, testUser, "+"+cmd.name), Command: cmd, Path: []string{cmd.name}})
+		if cmd == failing {
+			if messages := session.messages(); len(messages) != 1 || messages[0] != "<@2> Unexpected error: database is gone" {
+				t.Errorf("Fail replied %q", messages)
+			}
+		}
+	}
+
+	tests := []struct {
+		name                     string
+		uses, errors, rejections int64
+	}{
+		{"stats-ok", 1, 0, 0},
+		{"stats-fail", 1, 1, 0},
+		{"stats-denied", 1, 0, 1},
+	}
+	for _, test := range tests {
+		stat := stats(test.name)
+		if stat.Uses != test.uses || stat.Errors != test.errors || stat.Rejections != test.rejections {
+			t.Errorf("%s: got %d uses, %d errors and %d rejections, want %d, %d and %d",
+				test.name, stat.Uses, stat.Errors, stat.Rejections, test.uses, test.errors, test.rejections)
+		}
+	}
+}
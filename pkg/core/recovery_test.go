@@ -0,0 +1,39 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	session := &fakeSession{}
+	cmd := &testCommand{
+		name: "broken",
+		execute: func(s common.MessageState) {
+			panic(strings.Repeat("broken ", 500))
+		},
+	}
+
+	message := "+broken " + strings.Repeat("a", 1900)
+	dispatch(&Invocation{State: newTestState(session, testUser, message), Command: cmd, Path: []string{"broken"}})
+
+	messages := session.messages()
+	match := regexp.MustCompile(`Something went wrong \(ref ([0-9A-F]{6})\)\.`).FindStringSubmatch(strings.Join(messages, "\n"))
+	if match == nil {
+		t.Fatalf("the user must be told the reference, got %q", messages)
+	}
+
+	reports := session.dms(testOwner)
+	if len(reports) != 1 {
+		t.Fatalf("the owner must receive one report, got %d", len(reports))
+	}
+	if !strings.Contains(reports[0], "ref "+match[1]) || !strings.Contains(reports[0], `command "broken"`) {
+		t.Errorf("the report must contain the reference and origin, got %q", reports[0])
+	}
+	if len(reports[0]) > 2000 {
+		t.Errorf("the report must fit into a message, got %d characters", len(reports[0]))
+	}
+}
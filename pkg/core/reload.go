@@ -0,0 +1,154 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/common"
+	"github.com/qysp/disgotify/pkg/services/reminderservice"
+)
+
+var (
+	// config is the current configuration, it is replaced on reload.
+	config   *common.Config
+	configMu sync.RWMutex
+
+	// reloadMu serializes reloads, so each one is based on the previous one.
+	reloadMu sync.Mutex
+)
+
+// currentConfig returns the current configuration, which must not be modified.
+func currentConfig() *common.Config {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return config
+}
+
+// setConfig replaces the current configuration.
+func setConfig(cfg *common.Config) {
+	configMu.Lock()
+	config = cfg
+	configMu.Unlock()
+}
+
+// ReloadConfig reads the configuration again and applies the settings which can be changed while running.
+// Invalid configurations are not applied. Settings which require a restart keep their current value.
+// Guilds which the guild allowlist doesn't allow anymore are left (or ignored in dormant mode).
+func ReloadConfig() (common.ConfigChanges, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	current := currentConfig()
+	next, err := common.LoadConfig(current.Path)
+	if err != nil {
+		return common.ConfigChanges{}, err
+	}
+
+	reloaded, changes := current.Reload(next)
+	setConfig(reloaded)
+
+	// The other parts are updated without holding configMu, so handlers reading the config are never blocked.
+	common.SetDebug(reloaded.Debug)
+	reminderservice.Reload(reloaded)
+	if Client != nil {
+		leaveUnlistedGuilds(Client, reloaded)
+	}
+
+	return changes, nil
+}
+
+// ListenReload reloads the configuration on SIGHUP.
+func ListenReload() {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	go func() {
+		for range hangup {
+			changes, err := ReloadConfig()
+			if err != nil {
+				common.Logger.Error("Cannot reload configuration:", err)
+				continue
+			}
+			common.Logger.Info("Reloaded configuration:", describeChanges(changes))
+		}
+	}()
+}
+
+// describeChanges returns the applied settings and the ones requiring a restart.
+func describeChanges(changes common.ConfigChanges) string {
+	if len(changes.Applied) == 0 && len(changes.Restart) == 0 {
+		return "nothing changed."
+	}
+
+	var parts []string
+	if len(changes.Applied) > 0 {
+		parts = append(parts, fmt.Sprintf("applied %s", strings.Join(changes.Applied, ", ")))
+	}
+	if len(changes.Restart) > 0 {
+		parts = append(parts, fmt.Sprintf("restart required for %s", strings.Join(changes.Restart, ", ")))
+	}
+	return strings.Join(parts, "; ") + "."
+}
+
+// Reload reloads the configuration and reports which settings changed.
+type Reload struct{}
+
+func (*Reload) Name() string {
+	return "reload"
+}
+
+func (*Reload) Aliases() []string {
+	return []string{}
+}
+
+func (*Reload) Description() string {
+	return "Reload the configuration and apply the settings which can be changed while running."
+}
+
+func (*Reload) Category() common.CommandCategory {
+	return common.CategoryGeneral
+}
+
+func (*Reload) Permission() common.PermissionLevel {
+	return common.PermissionDeveloper
+}
+
+func (*Reload) Active() bool {
+	return true
+}
+
+func (*Reload) Execute(s common.MessageState) {
+	changes, err := ReloadConfig()
+	if err != nil {
+		common.Logger.Error("Cannot reload configuration:", err)
+		s.SendEmbed(&disgord.Embed{
+			Title:       "The configuration was not reloaded",
+			Description: "```\n" + err.Error() + "```",
+			Color:       0xe5004c,
+		})
+		return
+	}
+
+	common.Logger.Info("Reloaded configuration:", describeChanges(changes))
+	s.Reply(fmt.Sprintf("Reloaded the configuration, %s", describeChanges(changes)))
+}
+
+func (*Reload) Usage() common.CommandUsage {
+	return common.CommandUsage{
+		Notes: []common.UsageNote{
+			{
+				Name: "Restart required",
+				Value: "DATABASE_DIR, DISCORD_TOKEN, HOLIDAY_DIR, INTERACTIONS_ADDR, DISCORD_PUBLIC_KEY, " +
+					"PLUGIN_DIR, WORKERS and QUEUE_SIZE only take effect after a restart.",
+			},
+		},
+		Examples: []common.UsageExample{
+			{Description: "Reloading the configuration"},
+		},
+	}
+}
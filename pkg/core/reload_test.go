@@ -0,0 +1,103 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+func TestDescribeChanges(t *testing.T) {
+	tests := []struct {
+		changes common.ConfigChanges
+		want    string
+	}{
+		{common.ConfigChanges{}, "nothing changed."},
+		{common.ConfigChanges{Applied: []string{"COMMAND_PREFIX", "DEBUG"}}, "applied COMMAND_PREFIX, DEBUG."},
+		{common.ConfigChanges{Restart: []string{"WORKERS"}}, "restart required for WORKERS."},
+		{
+			common.ConfigChanges{Applied: []string{"DEBUG"}, Restart: []string{"WORKERS"}},
+			"applied DEBUG; restart required for WORKERS.",
+		},
+	}
+
+	for _, test := range tests {
+		if got := describeChanges(test.changes); got != test.want {
+			t.Errorf("describeChanges(%+v) = %q, want %q", test.changes, got, test.want)
+		}
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "disgotify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	write := func(content string) {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{"discord_token": "token", "database_dir": "` + dir + `", "command_prefix": "!", "workers": 2}`)
+
+	initial, err := common.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := currentConfig()
+	setConfig(initial)
+	defer setConfig(saved)
+
+	write(`{"discord_token": "token", "database_dir": "` + dir + `", "command_prefix": "+", "workers": 8}`)
+	changes, err := ReloadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"COMMAND_PREFIX"}; !reflect.DeepEqual(changes.Applied, want) {
+		t.Errorf("applied %v, want %v", changes.Applied, want)
+	}
+	if want := []string{"WORKERS"}; !reflect.DeepEqual(changes.Restart, want) {
+		t.Errorf("restart required for %v, want %v", changes.Restart, want)
+	}
+	if cfg := currentConfig(); cfg.CommandPrefix != "+" || cfg.Workers != 2 {
+		t.Errorf("got prefix %q and %d workers, want + and 2", cfg.CommandPrefix, cfg.Workers)
+	}
+
+	// Reloading again must not block, even though the reminder service doesn't run.
+	if _, err := ReloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	write(`{"discord_token": "token", "database_dir": "` + dir + `", "command_prefix": "?", "workers": "many"}`)
+	if _, err := ReloadConfig(); err == nil {
+		t.Error("invalid configurations must be rejected")
+	}
+	if cfg := currentConfig(); cfg.CommandPrefix != "+" {
+		t.Errorf("an invalid configuration was applied, got prefix %q", cfg.CommandPrefix)
+	}
+}
+
+func TestLeaveUnlistedGuilds(t *testing.T) {
+	const (
+		allowed  = disgord.Snowflake(600)
+		unlisted = disgord.Snowflake(601)
+	)
+	session := &fakeSession{guilds: []disgord.Snowflake{allowed, unlisted}}
+
+	leaveUnlistedGuilds(session, &common.Config{})
+	if len(session.left) != 0 {
+		t.Errorf("without an allowlist all guilds must be kept, left %v", session.left)
+	}
+
+	leaveUnlistedGuilds(session, &common.Config{OwnerIDs: []disgord.Snowflake{testOwner}, GuildAllowlist: []disgord.Snowflake{allowed}})
+	if want := []disgord.Snowflake{unlisted}; !reflect.DeepEqual(session.left, want) {
+		t.Errorf("left %v, want %v", session.left, want)
+	}
+}
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"encoding/json"
+
+	"github.com/qysp/disgotify/pkg/commands"
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+// OptionType represents the type of a slash command option.
+type OptionType uint
+
+// Slash command option type.
+const (
+	OptionSubCommand      OptionType = 1
+	OptionSubCommandGroup OptionType = 2
+	OptionString          OptionType = 3
+	OptionInteger         OptionType = 4
+	OptionUser            OptionType = 6
+	OptionChannel         OptionType = 7
+	OptionRole            OptionType = 8
+)
+
+const (
+	// maxDescriptionLength is the maximum length of slash command and option descriptions.
+	maxDescriptionLength = 100
+	// maxChoices is the maximum number of choices of an option.
+	maxChoices = 25
+)
+
+// ApplicationCommand represents the registration of a slash command.
+type ApplicationCommand struct {
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	Options     []ApplicationCommandOption `json:"options,omitempty"`
+}
+
+// ApplicationCommandOption represents an option or subcommand of a slash command.
+type ApplicationCommandOption struct {
+	Type        OptionType                 `json:"type"`
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	Required    bool                       `json:"required,omitempty"`
+	Choices     []ApplicationCommandChoice `json:"choices,omitempty"`
+	Options     []ApplicationCommandOption `json:"options,omitempty"`
+}
+
+// ApplicationCommandChoice represents a predefined value of an option.
+type ApplicationCommandChoice struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ApplicationCommands returns the slash command registrations of all commands in the command list.
+func ApplicationCommands() []ApplicationCommand {
+	var registrations []ApplicationCommand
+	for _, cmd := range commands.CommandList {
+		registrations = append(registrations, ApplicationCommand{
+			Name:        cmd.Name(),
+			Description: truncateDescription(cmd.Description()),
+			Options:     commandOptions(cmd),
+		})
+	}
+	return registrations
+}
+
+// ApplicationCommandsJSON returns the slash command registrations as JSON, ready to be sent to Discord.
+func ApplicationCommandsJSON() ([]byte, error) {
+	return json.MarshalIndent(ApplicationCommands(), "", "  ")
+}
+
+// commandOptions returns the options of a command, subcommands of groups become nested options.
+func commandOptions(cmd commands.Command) []ApplicationCommandOption {
+	var options []ApplicationCommandOption
+
+	if group, ok := cmd.(*commands.Group); ok {
+		for _, sub := range group.Subcommands() {
+			optionType := OptionSubCommand
+			if _, ok := sub.(*commands.Group); ok {
+				optionType = OptionSubCommandGroup
+			}
+			options = append(options, ApplicationCommandOption{
+				Type:        optionType,
+				Name:        sub.Name(),
+				Description: truncateDescription(sub.Description()),
+				Options:     commandOptions(sub),
+			})
+		}
+		return options
+	}
+
+	for _, arg := range cmd.Usage().Arguments {
+		option := ApplicationCommandOption{
+			Type:        argumentOptionType(arg),
+			Name:        arg.Name,
+			Description: truncateDescription(arg.Description),
+			Required:    !arg.Optional,
+		}
+		for i, choice := range arg.Choices {
+			if i == maxChoices {
+				break
+			}
+			option.Choices = append(option.Choices, ApplicationCommandChoice{
+				Name:  choice,
+				Value: choice,
+			})
+		}
+		options = append(options, option)
+	}
+	return options
+}
+
+// argumentOptionType returns the option type of an argument, custom parsed arguments are strings.
+func argumentOptionType(arg common.Argument) OptionType {
+	if arg.Parse != nil {
+		return OptionString
+	}
+
+	switch arg.Type {
+	case common.ArgumentInteger:
+		return OptionInteger
+	case common.ArgumentUser:
+		return OptionUser
+	case common.ArgumentChannel:
+		return OptionChannel
+	case common.ArgumentRole:
+		return OptionRole
+	}
+	return OptionString
+}
+
+// truncateDescription shortens a description to the length allowed by Discord.
+func truncateDescription(description string) string {
+	runes := []rune(description)
+	if len(runes) <= maxDescriptionLength {
+		return description
+	}
+	return string(runes[:maxDescriptionLength-3]) + "..."
+}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qysp/disgotify/pkg/commands"
+	"github.com/qysp/disgotify/pkg/common"
+)
+
+// slowCommand is a command declaring its own timeout.
+type slowCommand struct {
+	testCommand
+	timeout time.Duration
+}
+
+func (c *slowCommand) Timeout() time.Duration {
+	return c.timeout
+}
+
+func TestCommandTimeout(t *testing.T) {
+	cfg := &common.Config{
+		CommandTimeout:  30 * time.Second,
+		CommandTimeouts: map[string]time.Duration{"stats": time.Minute},
+	}
+
+	tests := []struct {
+		cmd  commands.Command
+		want time.Duration
+	}{
+		{&testCommand{name: "ping"}, 30 * time.Second},
+		{&slowCommand{testCommand: testCommand{name: "remind"}, timeout: 10 * time.Second}, 10 * time.Second},
+		{&slowCommand{testCommand: testCommand{name: "stats"}, timeout: 10 * time.Second}, time.Minute},
+		{commands.Named("add", nil, &slowCommand{testCommand: testCommand{name: "remind"}, timeout: 5 * time.Second}), 5 * time.Second},
+		{commands.Named("usage", nil, &testCommand{name: "stats"}), time.Minute},
+	}
+
+	for _, test := range tests {
+		if timeout := commandTimeout(cfg, test.cmd); timeout != test.want {
+			t.Errorf("commandTimeout(%s) = %s, want %s", test.cmd.Name(), timeout, test.want)
+		}
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	release := make(chan struct{})
+	cancelled := make(chan struct{})
+	cmd := &slowCommand{
+		testCommand: testCommand{name: "slow", execute: func(s common.MessageState) {
+			<-s.Context().Done()
+			close(cancelled)
+			<-release
+			// Messages of a timed out command are dropped.
+			s.Send("too late")
+		}},
+		timeout: 20 * time.Millisecond,
+	}
+
+	session := &fakeSession{}
+	inv := &Invocation{State: newTestState(session, testUser, "+slow"), Command: cmd, Path: []string{"slow"}}
+
+	start := time.Now()
+	TimeoutMiddleware(func(inv *Invocation) {
+		cmd.Execute(inv.State)
+	})(inv)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("the middleware returned after %s", elapsed)
+	}
+	if !inv.timedOut {
+		t.Error("the invocation must be marked as timed out")
+	}
+
+	<-cancelled
+	close(release)
+	time.Sleep(10 * time.Millisecond)
+
+	messages := session.messages()
+	if len(messages) != 1 || !strings.Contains(messages[0], "timed out") {
+		t.Errorf("got %q, want only the timeout reply", messages)
+	}
+}
+
+func TestRunningCommands(t *testing.T) {
+	release := make(chan struct{})
+	cmd := &slowCommand{
+		testCommand: testCommand{name: "stuck", execute: func(s common.MessageState) {
+			<-release
+		}},
+		timeout: 10 * time.Millisecond,
+	}
+
+	inv := &Invocation{State: newTestState(&fakeSession{}, testUser, "+stuck"), Command: cmd, Path: []string{"stuck"}}
+	TimeoutMiddleware(func(inv *Invocation) {
+		cmd.Execute(inv.State)
+	})(inv)
+
+	done := waitDone(&runningCommands)
+	select {
+	case <-done:
+		t.Error("commands which ignore their context must still be awaited")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("the command returned, but is still counted")
+	}
+}
+
+// waitDone returns a channel which is closed once wg is done.
+func waitDone(wg *sync.WaitGroup) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	return done
+}
+
+// useRootContext replaces the root context for a test and returns a function restoring it.
+func useRootContext() func() {
+	savedContext, savedCancel := rootContext, cancelRoot
+	rootContext, cancelRoot = context.WithCancel(context.Background())
+	return func() {
+		cancelRoot()
+		rootContext, cancelRoot = savedContext, savedCancel
+	}
+}
+
+func TestFinishCommands(t *testing.T) {
+	defer useRootContext()()
+
+	var mu sync.Mutex
+	var finished []string
+	cmd := &testCommand{name: "queued", execute: func(s common.MessageState) {
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		if s.Context().Err() == nil {
+			finished = append(finished, s.Message())
+		}
+	}}
+
+	session := &fakeSession{}
+	queue := common.NewWorkerPool(1, 10)
+	for _, content := range []string{"+queued 1", "+queued 2", "+queued 3"} {
+		inv := &Invocation{State: newTestState(session, testUser, content), Command: cmd, Path: []string{"queued"}}
+		queue.Submit(testUser, func() { TimeoutMiddleware(func(inv *Invocation) { cmd.Execute(inv.State) })(inv) })
+	}
+
+	if !finishCommands(queue, time.Second) {
+		t.Fatal("the commands must finish in time")
+	}
+	if len(finished) != 3 || len(session.messages()) != 0 {
+		t.Errorf("queued commands must run before shutting down, finished %q, replies %q", finished, session.messages())
+	}
+}
+
+func TestFinishCommandsCancel(t *testing.T) {
+	defer useRootContext()()
+
+	cmd := &testCommand{name: "endless", execute: func(s common.MessageState) {
+		<-s.Context().Done()
+	}}
+
+	session := &fakeSession{}
+	queue := common.NewWorkerPool(1, 10)
+	inv := &Invocation{State: newTestState(session, testUser, "+endless"), Command: cmd, Path: []string{"endless"}}
+	queue.Submit(testUser, func() { TimeoutMiddleware(func(inv *Invocation) { cmd.Execute(inv.State) })(inv) })
+
+	start := time.Now()
+	if !finishCommands(queue, 50*time.Millisecond) {
+		t.Fatal("cancelled commands must be awaited")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("finishing took %s", elapsed)
+	}
+	if messages := session.messages(); len(messages) != 1 || !strings.Contains(messages[0], "shutting down") {
+		t.Errorf("got %q, want the shutdown reply", messages)
+	}
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"github.com/andersfylling/disgord"
+	"github.com/jinzhu/gorm"
+)
+
+// Alias represents a user- or guild-defined shortcut for a command.
+// Personal aliases have a UserID, guild aliases a GuildID.
+type Alias struct {
+	gorm.Model
+	UserID    disgord.Snowflake `gorm:"index"`
+	GuildID   disgord.Snowflake `gorm:"index"`
+	Name      string
+	Expansion string
+}
+
+// TableName name of the table for aliases.
+func (Alias) TableName() string {
+	return "aliases"
+}
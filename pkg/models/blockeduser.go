@@ -0,0 +1,19 @@
+package models
+
+import (
+	"github.com/andersfylling/disgord"
+	"github.com/jinzhu/gorm"
+)
+
+// BlockedUser represents a user the bot ignores.
+type BlockedUser struct {
+	gorm.Model
+	UserID    disgord.Snowflake `gorm:"unique_index"`
+	Reason    string
+	BlockedBy disgord.Snowflake
+}
+
+// TableName name of the table for blocked users.
+func (BlockedUser) TableName() string {
+	return "blocked_users"
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"github.com/andersfylling/disgord"
+	"github.com/jinzhu/gorm"
+)
+
+// Command rule target type.
+const (
+	RuleTargetUser    = "user"
+	RuleTargetRole    = "role"
+	RuleTargetChannel = "channel"
+)
+
+// CommandRule represents a guild's rule allowing or denying a command to a user, role or channel.
+type CommandRule struct {
+	gorm.Model
+	GuildID disgord.Snowflake `gorm:"index"`
+	// Command is the full name of the command, e.g. "reminder add".
+	Command    string
+	TargetType string
+	TargetID   disgord.Snowflake
+	Allow      bool
+}
+
+// TableName name of the table for command rules.
+func (CommandRule) TableName() string {
+	return "command_rules"
+}
+
+// Target returns the mention of the rule's user, role or channel.
+func (r *CommandRule) Target() string {
+	switch r.TargetType {
+	case RuleTargetUser:
+		return "<@" + r.TargetID.String() + ">"
+	case RuleTargetRole:
+		return "<@&" + r.TargetID.String() + ">"
+	}
+	return "<#" + r.TargetID.String() + ">"
+}
+
+// Effect returns "allow" or "deny".
+func (r *CommandRule) Effect() string {
+	if r.Allow {
+		return "allow"
+	}
+	return "deny"
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"github.com/andersfylling/disgord"
+	"github.com/jinzhu/gorm"
+)
+
+// CustomCommand represents a guild-defined command replying with a rendered text template.
+type CustomCommand struct {
+	gorm.Model
+	GuildID   disgord.Snowflake `gorm:"index"`
+	Name      string
+	Template  string
+	CreatedBy disgord.Snowflake
+}
+
+// TableName name of the table for custom commands.
+func (CustomCommand) TableName() string {
+	return "custom_commands"
+}
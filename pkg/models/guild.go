@@ -0,0 +1,22 @@
+package models
+
+import (
+	"github.com/andersfylling/disgord"
+	"github.com/jinzhu/gorm"
+)
+
+// GuildSettings represents the per guild configuration.
+type GuildSettings struct {
+	gorm.Model
+	GuildID         disgord.Snowflake `gorm:"unique_index"`
+	HolidayCalendar string
+	// DisableSuggestions turns off "did you mean" replies to unknown commands.
+	DisableSuggestions bool
+	// Prefix replaces the global command prefix in this guild if set.
+	Prefix string
+}
+
+// TableName name of the table for guild settings.
+func (GuildSettings) TableName() string {
+	return "guild_settings"
+}
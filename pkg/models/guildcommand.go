@@ -0,0 +1,62 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/andersfylling/disgord"
+	"github.com/jinzhu/gorm"
+)
+
+// GuildCommand represents the configuration of a command in a guild.
+type GuildCommand struct {
+	gorm.Model
+	GuildID  disgord.Snowflake `gorm:"unique_index:idx_guild_command"`
+	Command  string            `gorm:"unique_index:idx_guild_command"`
+	Disabled bool
+	// Channels is a comma separated list of channel IDs the command is restricted to, empty if unrestricted.
+	Channels string
+}
+
+// TableName name of the table for guild command configurations.
+func (GuildCommand) TableName() string {
+	return "guild_commands"
+}
+
+// ChannelIDs returns the IDs of the channels the command is restricted to.
+func (c *GuildCommand) ChannelIDs() []disgord.Snowflake {
+	var ids []disgord.Snowflake
+	for _, id := range strings.Split(c.Channels, ",") {
+		if id == "" {
+			continue
+		}
+		if snowflake, err := disgord.GetSnowflake(id); err == nil {
+			ids = append(ids, snowflake)
+		}
+	}
+	return ids
+}
+
+// AllowsChannel returns a bool which indicates whether the command may be used in the channel.
+func (c *GuildCommand) AllowsChannel(channelID disgord.Snowflake) bool {
+	ids := c.ChannelIDs()
+	if len(ids) == 0 {
+		return true
+	}
+	for _, id := range ids {
+		if id == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+// AddChannel restricts the command to an additional channel.
+func (c *GuildCommand) AddChannel(channelID disgord.Snowflake) {
+	if len(c.ChannelIDs()) > 0 && c.AllowsChannel(channelID) {
+		return
+	}
+	if c.Channels != "" {
+		c.Channels += ","
+	}
+	c.Channels += channelID.String()
+}
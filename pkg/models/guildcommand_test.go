@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/andersfylling/disgord"
+)
+
+func TestGuildCommandChannels(t *testing.T) {
+	config := &GuildCommand{}
+	if !config.AllowsChannel(1) {
+		t.Error("an unrestricted command must be allowed everywhere")
+	}
+
+	config.AddChannel(1)
+	config.AddChannel(2)
+	config.AddChannel(1)
+	if config.Channels != "1,2" {
+		t.Errorf("Channels = %q, want 1,2", config.Channels)
+	}
+	if !config.AllowsChannel(2) || config.AllowsChannel(3) {
+		t.Error("a restricted command must only be allowed in its channels")
+	}
+
+	config.Channels = "1,,3"
+	if ids := config.ChannelIDs(); len(ids) != 2 || ids[1] != disgord.Snowflake(3) {
+		t.Errorf("ChannelIDs = %v, empty IDs must be skipped", ids)
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"github.com/andersfylling/disgord"
+	"github.com/jinzhu/gorm"
+)
+
+// GuildRole represents the permission level granted by a guild's role.
+type GuildRole struct {
+	gorm.Model
+	GuildID disgord.Snowflake `gorm:"index"`
+	RoleID  disgord.Snowflake
+	// Level is a common.PermissionLevel.
+	Level uint
+}
+
+// TableName name of the table for guild roles.
+func (GuildRole) TableName() string {
+	return "guild_roles"
+}
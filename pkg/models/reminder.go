@@ -14,18 +14,49 @@ const (
 	RepeatMinutely
 	RepeatHourly
 	RepeatDaily
+	RepeatWeekdays
+	RepeatBusinessDays
+	RepeatWeekly
 )
 
 // Reminder represents the structure for a reminder.
 type Reminder struct {
 	gorm.Model
-	UserID       disgord.Snowflake
+	UserID disgord.Snowflake
+	// GuildID is the guild the reminder was created in, empty if it was created in a DM.
+	GuildID      disgord.Snowflake `gorm:"index"`
 	Due          int64
 	Notification string
 	Repeat       RepeatInterval
+	// Calendar is the holiday calendar used to skip holidays on business day repeats.
+	Calendar string
+	// GroupID is the ID of the first schedule slot of a reminder with multiple slots, 0 otherwise.
+	GroupID uint `gorm:"index"`
 }
 
 // TableName name of the table for reminders.
 func (Reminder) TableName() string {
 	return "reminders"
 }
+
+// GroupReminders groups the schedule slots of reminders into logical reminders.
+// The order of the first slot of each reminder is kept.
+func GroupReminders(reminders []Reminder) [][]Reminder {
+	var groups [][]Reminder
+	positions := map[uint]int{}
+	for _, reminder := range reminders {
+		if reminder.GroupID == 0 {
+			groups = append(groups, []Reminder{reminder})
+			continue
+		}
+
+		if pos, ok := positions[reminder.GroupID]; ok {
+			groups[pos] = append(groups[pos], reminder)
+			continue
+		}
+
+		positions[reminder.GroupID] = len(groups)
+		groups = append(groups, []Reminder{reminder})
+	}
+	return groups
+}
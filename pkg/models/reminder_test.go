@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/jinzhu/gorm"
+)
+
+func TestGroupReminders(t *testing.T) {
+	reminder := func(id, group uint) Reminder {
+		return Reminder{Model: gorm.Model{ID: id}, GroupID: group}
+	}
+	reminders := []Reminder{
+		reminder(1, 0),
+		reminder(2, 2),
+		reminder(3, 2),
+		reminder(4, 0),
+		reminder(5, 5),
+		reminder(6, 2),
+		reminder(7, 5),
+	}
+
+	groups := GroupReminders(reminders)
+	want := [][]uint{{1}, {2, 3, 6}, {4}, {5, 7}}
+	if len(groups) != len(want) {
+		t.Fatalf("got %d groups, want %d", len(groups), len(want))
+	}
+	for i, group := range groups {
+		if len(group) != len(want[i]) {
+			t.Fatalf("group %d has %d slots, want %d", i, len(group), len(want[i]))
+		}
+		for j, slot := range group {
+			if slot.ID != want[i][j] {
+				t.Errorf("group %d slot %d has ID %d, want %d", i, j, slot.ID, want[i][j])
+			}
+		}
+	}
+}
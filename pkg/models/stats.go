@@ -0,0 +1,42 @@
+package models
+
+import (
+	"github.com/andersfylling/disgord"
+)
+
+// CommandStat represents the aggregated invocations of a command by a user per day.
+type CommandStat struct {
+	ID uint `gorm:"primary_key"`
+	// Day is the unix timestamp of the day's start (UTC).
+	Day     int64             `gorm:"index"`
+	Command string            `gorm:"index"`
+	GuildID disgord.Snowflake `gorm:"index"`
+	UserID  disgord.Snowflake
+	// DM is true for invocations in direct messages.
+	DM   bool
+	Uses int64
+	// Errors counts the invocations which panicked, timed out or replied with an unexpected error.
+	Errors int64
+	// Rejections counts the invocations stopped by a middleware, e.g. missing permissions.
+	Rejections int64
+	// Duration is the summed up execution time in milliseconds.
+	Duration int64
+}
+
+// TableName name of the table for command statistics.
+func (CommandStat) TableName() string {
+	return "command_stats"
+}
+
+// ReminderStat represents the number of reminders created and delivered per day.
+type ReminderStat struct {
+	ID        uint  `gorm:"primary_key"`
+	Day       int64 `gorm:"unique_index"`
+	Created   int64
+	Delivered int64
+}
+
+// TableName name of the table for reminder statistics.
+func (ReminderStat) TableName() string {
+	return "reminder_stats"
+}
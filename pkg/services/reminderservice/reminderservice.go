@@ -2,6 +2,7 @@ package reminderservice
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/andersfylling/disgord"
@@ -13,10 +14,23 @@ import (
 var ticker *time.Ticker
 var stopped = make(chan bool, 1)
 
-// Start creates a new ticker with the duration of interval
+// reloaded receives reloaded configs, which replace config and the ticker.
+var reloaded = make(chan *common.Config, 1)
+
+// reloadMu serializes Reload, which replaces the pending config.
+var reloadMu sync.Mutex
+
+// config is the current configuration, it is only accessed by the service's goroutine.
+var config *common.Config
+
+// failed represents the IDs of reminders which caused a panic.
+var failed = map[uint]bool{}
+
+// Start creates a new ticker with the configured reminder interval
 // and starts a goroutine which sends reminders if they are due.
-func Start(client *disgord.Client, interval time.Duration) {
-	ticker = time.NewTicker(interval)
+func Start(client *disgord.Client, cfg *common.Config) {
+	config = cfg
+	ticker = time.NewTicker(cfg.ReminderInterval)
 
 	// Gets stopped if Stop() gets called.
 	go func() {
@@ -24,6 +38,12 @@ func Start(client *disgord.Client, interval time.Duration) {
 			select {
 			case <-ticker.C:
 				sendReminders(client)
+			case cfg := <-reloaded:
+				if cfg.ReminderInterval != config.ReminderInterval {
+					ticker.Stop()
+					ticker = time.NewTicker(cfg.ReminderInterval)
+				}
+				config = cfg
 			case <-stopped:
 				ticker.Stop()
 				return
@@ -32,7 +52,15 @@ func Start(client *disgord.Client, interval time.Duration) {
 	}()
 }
 
+// sendReminders sends all due reminders.
+// Panics are recovered, so the reminder service keeps running on the next tick.
 func sendReminders(client *disgord.Client) {
+	defer func() {
+		if cause := recover(); cause != nil {
+			common.ReportPanic(client, config.OwnerIDs, cause, "reminder service")
+		}
+	}()
+
 	var reminders []models.Reminder
 	err := common.DB.Where("due <= ?", time.Now().Unix()).Find(&reminders).Error
 	if err != nil {
@@ -45,43 +73,110 @@ func sendReminders(client *disgord.Client) {
 
 	// Iterate over reminders, create a DM channel with a user and send the notification.
 	for _, reminder := range reminders {
-		ch, err := client.CreateDM(reminder.UserID)
-		if err != nil {
-			client.Logger().Error(err)
-			continue
+		sendReminder(client, reminder)
+	}
+}
+
+// sendReminder creates a DM channel with a user and sends the notification.
+// Panics are recovered, so a single broken reminder doesn't stop the reminder service.
+func sendReminder(client *disgord.Client, reminder models.Reminder) {
+	defer func() {
+		cause := recover()
+		if cause == nil {
+			return
 		}
-		created, _ := goment.New(reminder.CreatedAt)
-		notification := fmt.Sprintf(
-			"[Reminder from %s at %s]: %s",
-			created.Format("Do MMMM YYYY"),
-			created.Format("HH:mm:ss"),
-			reminder.Notification,
-		)
-
-		_, err = client.SendMsg(ch.ID, notification)
+
+		// Only report the first panic of a reminder, it will most likely panic on every tick.
+		if failed[reminder.ID] {
+			return
+		}
+		failed[reminder.ID] = true
+		common.ReportPanic(client, config.OwnerIDs, cause, fmt.Sprintf("reminder service (reminder ID %d)", reminder.ID))
+	}()
+
+	// Reminders of blocked users and from guilds which are not allowed are skipped,
+	// but still rescheduled, so they don't pile up until unblocking.
+	skip := common.IsBlocked(reminder.UserID) || !config.IsGuildAllowed(reminder.GuildID)
+	if !skip && !deliver(client, reminder) {
+		return
+	}
+
+	if reminder.Repeat > models.NoRepeat {
+		common.DB.Model(&reminder).Updates(models.Reminder{
+			Due: NextDue(reminder.Due, reminder.Repeat, reminder.Calendar),
+		})
+	} else {
+		err := common.DB.Unscoped().Delete(&reminder).Error
 		if err != nil {
 			client.Logger().Error(err)
 		}
+	}
+}
 
-		if reminder.Repeat > models.NoRepeat {
-			g, _ := goment.Unix(reminder.Due)
-			switch reminder.Repeat {
-			case models.RepeatMinutely:
-				g.Add(1, "minute")
-			case models.RepeatHourly:
-				g.Add(1, "hour")
-			case models.RepeatDaily:
-				g.Add(1, "day")
-			}
+// deliver sends the notification of a reminder and returns false if no DM channel could be created.
+func deliver(client *disgord.Client, reminder models.Reminder) bool {
+	ch, err := client.CreateDM(reminder.UserID)
+	if err != nil {
+		client.Logger().Error(err)
+		return false
+	}
+	created, _ := goment.New(reminder.CreatedAt)
+	notification := fmt.Sprintf(
+		"[Reminder from %s at %s]: %s",
+		created.Format("Do MMMM YYYY"),
+		created.Format("HH:mm:ss"),
+		reminder.Notification,
+	)
 
-			common.DB.Model(&reminder).Updates(models.Reminder{
-				Due: g.ToUnix(),
-			})
-		} else {
-			err = common.DB.Unscoped().Delete(&reminder).Error
-			if err != nil {
-				client.Logger().Error(err)
-			}
+	_, err = client.SendMsg(ch.ID, notification)
+	if err != nil {
+		client.Logger().Error(err)
+	} else if err := common.RecordReminders(0, 1); err != nil {
+		client.Logger().Error(err)
+	}
+	return true
+}
+
+// NextDue returns the unix timestamp following due for a repeat interval.
+// Business day repeats skip weekends and the holidays of calendar.
+func NextDue(due int64, repeat models.RepeatInterval, calendar string) int64 {
+	g, _ := goment.Unix(due)
+	switch repeat {
+	case models.RepeatMinutely:
+		g.Add(1, "minute")
+	case models.RepeatHourly:
+		g.Add(1, "hour")
+	case models.RepeatDaily:
+		g.Add(1, "day")
+	case models.RepeatWeekly:
+		g.Add(1, "week")
+	case models.RepeatWeekdays:
+		t := g.ToTime().AddDate(0, 0, 1)
+		for !common.IsWeekday(t) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t.Unix()
+	case models.RepeatBusinessDays:
+		return common.NextBusinessDay(calendar, g.ToTime()).Unix()
+	}
+	return g.ToUnix()
+}
+
+// Reload passes a reloaded config to the running service without blocking, a changed reminder interval restarts the ticker.
+func Reload(cfg *common.Config) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	// A config the service didn't pick up yet is outdated, so it is replaced.
+	for {
+		select {
+		case reloaded <- cfg:
+			return
+		default:
+		}
+		select {
+		case <-reloaded:
+		default:
 		}
 	}
 }
@@ -0,0 +1,130 @@
+package reminderservice
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/andersfylling/disgord"
+	"github.com/qysp/disgotify/pkg/common"
+	"github.com/qysp/disgotify/pkg/models"
+)
+
+func TestMain(m *testing.M) {
+	common.InitNopLogger()
+
+	dir, err := ioutil.TempDir("", "disgotify")
+	if err != nil {
+		panic(err)
+	}
+	common.InitDB(dir)
+	config = &common.Config{}
+
+	code := m.Run()
+
+	common.DB.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// storeReminder stores a due reminder and returns it.
+func storeReminder(t *testing.T, userID, guildID disgord.Snowflake, repeat models.RepeatInterval) models.Reminder {
+	reminder := models.Reminder{
+		UserID:       userID,
+		GuildID:      guildID,
+		Due:          time.Now().Add(-time.Minute).Unix(),
+		Notification: "test",
+		Repeat:       repeat,
+	}
+	if err := common.DB.Create(&reminder).Error; err != nil {
+		t.Fatal(err)
+	}
+	return reminder
+}
+
+// findReminder returns the stored reminder by ID and whether it still exists.
+func findReminder(t *testing.T, id uint) (models.Reminder, bool) {
+	var reminder models.Reminder
+	err := common.DB.Where("id = ?", id).Find(&reminder).Error
+	return reminder, err == nil
+}
+
+func TestNextDue(t *testing.T) {
+	// Friday 9:30.
+	friday := time.Date(2025, time.May, 23, 9, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		repeat models.RepeatInterval
+		want   time.Time
+	}{
+		{models.RepeatMinutely, friday.Add(time.Minute)},
+		{models.RepeatHourly, friday.Add(time.Hour)},
+		{models.RepeatDaily, friday.AddDate(0, 0, 1)},
+		{models.RepeatWeekly, friday.AddDate(0, 0, 7)},
+		{models.RepeatWeekdays, friday.AddDate(0, 0, 3)},
+		// Without a calendar business days are weekdays.
+		{models.RepeatBusinessDays, friday.AddDate(0, 0, 3)},
+	}
+
+	for _, test := range tests {
+		if got := NextDue(friday.Unix(), test.repeat, ""); got != test.want.Unix() {
+			t.Errorf("NextDue(%d) = %s, want %s", test.repeat, time.Unix(got, 0), test.want)
+		}
+	}
+}
+
+func TestSendReminderBlocked(t *testing.T) {
+	const user = disgord.Snowflake(60)
+	if err := common.BlockUser(user, 1, ""); err != nil {
+		t.Fatal(err)
+	}
+	defer common.UnblockUser(user)
+
+	// The client is never used since nothing is delivered.
+	once := storeReminder(t, user, 0, models.NoRepeat)
+	sendReminder(nil, once)
+	if _, ok := findReminder(t, once.ID); ok {
+		t.Error("skipped reminders must be removed like delivered ones")
+	}
+
+	daily := storeReminder(t, user, 0, models.RepeatDaily)
+	sendReminder(nil, daily)
+	reminder, ok := findReminder(t, daily.ID)
+	if !ok || reminder.Due != NextDue(daily.Due, models.RepeatDaily, "") {
+		t.Errorf("skipped repeating reminders must be rescheduled, got %+v", reminder)
+	}
+}
+
+func TestSendReminderUnlistedGuild(t *testing.T) {
+	defer func(cfg *common.Config) { config = cfg }(config)
+	config = &common.Config{GuildAllowlist: []disgord.Snowflake{500}}
+
+	once := storeReminder(t, 2, 501, models.NoRepeat)
+	sendReminder(nil, once)
+	if _, ok := findReminder(t, once.ID); ok {
+		t.Error("reminders from unlisted guilds must be skipped and removed")
+	}
+}
+
+func TestReload(t *testing.T) {
+	first, latest := &common.Config{CommandPrefix: "!"}, &common.Config{CommandPrefix: "+"}
+
+	// The service doesn't run, so only the latest config may be pending.
+	Reload(first)
+	Reload(latest)
+
+	select {
+	case cfg := <-reloaded:
+		if cfg != latest {
+			t.Errorf("got prefix %q, want the latest config", cfg.CommandPrefix)
+		}
+	default:
+		t.Fatal("no config is pending")
+	}
+	select {
+	case cfg := <-reloaded:
+		t.Errorf("the outdated config with prefix %q is still pending", cfg.CommandPrefix)
+	default:
+	}
+}